@@ -2,8 +2,11 @@ package main
 
 import (
 	"github.com/alecthomas/kingpin/v2"
+	"github.com/msharbaji/grpc-go-example/internal/repositories"
+	"github.com/msharbaji/grpc-go-example/internal/server"
 	"github.com/msharbaji/grpc-go-example/pkg/app"
 	"github.com/rs/zerolog/log"
+	"time"
 )
 
 const (
@@ -13,7 +16,28 @@ const (
 
 var (
 	grpcPort    = kingpin.Flag("grpc-port", "gRPC port").Envar("GRPC_PORT").Default("50051").String()
+	userStore   = kingpin.Flag("user-store", "User storage backend").Envar("USER_STORE").Default(app.UserStoreMemory).Enum(app.UserStoreMemory, app.UserStoreSQLite, app.UserStorePostgres)
+	sqliteDSN   = kingpin.Flag("sqlite-dsn", "SQLite database for the sqlite user store").Envar("SQLITE_DSN").Default("file:users.db?_pragma=busy_timeout(5000)").String()
+	postgresDSN = kingpin.Flag("postgres-dsn", "Postgres database for the postgres user store").Envar("POSTGRES_DSN").Default("postgres://localhost:5432/users?sslmode=disable").String()
+	pgMaxOpen   = kingpin.Flag("postgres-max-open-conns", "Maximum open connections to Postgres, 0 for unlimited").Envar("POSTGRES_MAX_OPEN_CONNS").Default("10").Int()
+	pgMaxIdle   = kingpin.Flag("postgres-max-idle-conns", "Maximum idle connections to Postgres").Envar("POSTGRES_MAX_IDLE_CONNS").Default("5").Int()
+	pgLifetime  = kingpin.Flag("postgres-conn-max-lifetime", "Maximum lifetime of a Postgres connection, 0 to keep connections forever").Envar("POSTGRES_CONN_MAX_LIFETIME").Default("30m").Duration()
+	pgIdleTime  = kingpin.Flag("postgres-conn-max-idle-time", "Maximum time a Postgres connection may stay idle, 0 to keep idle connections forever").Envar("POSTGRES_CONN_MAX_IDLE_TIME").Default("5m").Duration()
+	pageSecret  = kingpin.Flag("page-token-secret", "Secret signing ListUsers page tokens, random when empty").Envar("PAGE_TOKEN_SECRET").String()
+	retention   = kingpin.Flag("purge-retention", "How long deleted users can be undeleted before being purged, 0 to never purge").Envar("PURGE_RETENTION").Default("720h").Duration()
+	purgeEvery  = kingpin.Flag("purge-interval", "How often deleted users past their retention are purged").Envar("PURGE_INTERVAL").Default("1h").Duration()
+	idempotency = kingpin.Flag("idempotency-window", "How long responses to calls with an x-idempotency-key are replayed, 0 to disable").Envar("IDEMPOTENCY_WINDOW").Default("24h").Duration()
 	hmacSecrets = kingpin.Flag("hmac-secrets", "Key-value pair for secret").Envar("HMAC_SECRETS").Default("my-secret-key=my-secret-value").StringMap()
+	secretsFile = kingpin.Flag("hmac-secrets-file", "JSON or YAML file of HMAC secrets with their validity periods, reloaded when it changes. Replaces --hmac-secrets").Envar("HMAC_SECRETS_FILE").String()
+	reloadEvery = kingpin.Flag("hmac-secrets-reload-interval", "How often the HMAC secrets file is checked for changes, 0 to never reload it").Envar("HMAC_SECRETS_RELOAD_INTERVAL").Default("10s").Duration()
+	hmacTenants = kingpin.Flag("hmac-tenants", "Key-value pair binding an HMAC key ID to its tenant, keys without one act in the default tenant. Not allowed with --hmac-secrets-file").Envar("HMAC_TENANTS").StringMap()
+	hmacRoles   = kingpin.Flag("hmac-roles", "Key-value pair giving an HMAC key ID its role: viewer (the default), editor or owner. Not allowed with --hmac-secrets-file").Envar("HMAC_ROLES").StringMap()
+	adminKeys   = kingpin.Flag("hmac-admin-keys", "HMAC key IDs that can act in any tenant with x-tenant metadata").Envar("HMAC_ADMIN_KEYS").Strings()
+	clockSkew   = kingpin.Flag("hmac-clock-skew", "How far the x-hmac-timestamp of a request may be from the server clock").Envar("HMAC_CLOCK_SKEW").Default("5m").Duration()
+	public      = kingpin.Flag("public-methods", "Methods callable without authentication, as full method names or service wildcards like /grpc.health.v1.Health/*").Envar("PUBLIC_METHODS").Default(server.DefaultPublicMethods...).Strings()
+	noLegacy    = kingpin.Flag("hmac-reject-legacy", "Reject requests signed with the legacy gob-based scheme (x-hmac-version 1), which can be replayed. Set it once every client signs with x-hmac-version 2").Envar("HMAC_REJECT_LEGACY").Bool()
+	signedMsgs  = kingpin.Flag("hmac-require-signed-messages", "Reject streams whose client doesn't sign each message it sends with x-hmac-signed-messages").Envar("HMAC_REQUIRE_SIGNED_MESSAGES").Bool()
+	cutoff      = kingpin.Flag("hmac-legacy-cutoff", "Date (YYYY-MM-DD, UTC) from which legacy signatures are rejected even without --hmac-reject-legacy").Envar("HMAC_LEGACY_CUTOFF").Default("2027-01-01").String()
 )
 
 func main() {
@@ -22,7 +46,39 @@ func main() {
 
 	log.Info().Str("AppVersion", version).Msg("starting api")
 
-	_app, err := app.NewApp(*grpcPort, *hmacSecrets)
+	legacyCutoff, err := time.Parse(time.DateOnly, *cutoff)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid --hmac-legacy-cutoff")
+	}
+
+	_app, err := app.NewApp(app.Config{
+		GrpcPort:    *grpcPort,
+		HmacSecrets: *hmacSecrets,
+		HmacTenants: *hmacTenants,
+		AdminKeys:   *adminKeys,
+		HmacRoles:   *hmacRoles,
+		UserStore:   *userStore,
+		SQLiteDSN:   *sqliteDSN,
+		PostgresDSN: *postgresDSN,
+		PostgresPool: repositories.PoolConfig{
+			MaxOpenConns:    *pgMaxOpen,
+			MaxIdleConns:    *pgMaxIdle,
+			ConnMaxLifetime: *pgLifetime,
+			ConnMaxIdleTime: *pgIdleTime,
+		},
+		PageTokenSecret:   *pageSecret,
+		PurgeRetention:    *retention,
+		PurgeInterval:     *purgeEvery,
+		IdempotencyWindow: *idempotency,
+
+		RejectLegacySignatures:    *noLegacy,
+		HmacLegacyCutoff:          legacyCutoff,
+		HmacSecretsFile:           *secretsFile,
+		HmacSecretsReloadInterval: *reloadEvery,
+		HmacClockSkew:             *clockSkew,
+		HmacRequireSignedMessages: *signedMsgs,
+		PublicMethods:             *public,
+	})
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to create app")
 	}
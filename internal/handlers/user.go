@@ -2,115 +2,132 @@ package handlers
 
 import (
 	"context"
-	"github.com/golang/protobuf/ptypes/timestamp"
-	"github.com/google/uuid"
+	"errors"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/internal/services"
+	"github.com/msharbaji/grpc-go-example/pkg/apierrors"
 	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"github.com/msharbaji/grpc-go-example/pkg/validate"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/types/known/emptypb"
-	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"strconv"
 )
 
-var users = map[string]*pb.User{
-	"someone": {
-		Id:       "1",
-		Username: "someone",
-		Email:    "someone@someone.com",
-	},
-	"someone_else": {
-		Id:       "2",
-		Username: "someone_else",
-		Email:    "someonce2@someone.com",
-		CreatedAt: &timestamp.Timestamp{
-			Seconds: 1612345678,
-		},
-		UpdatedAt: &timestamp.Timestamp{
-			Seconds: 1612345678,
-		},
-	},
-}
+const (
+	// maxBulkCreateUsers caps the number of users of a BulkCreateUsers call
+	maxBulkCreateUsers = 10000
+	// watchRevisionHeader is the header metadata of WatchUsers streams holding
+	// the revision the watch starts after
+	watchRevisionHeader = "x-watch-revision"
+)
 
 // UserServiceServer is the user service server
 type userServiceServer struct {
 	pb.UnimplementedUserServiceServer
+	users *services.UserService
 }
 
 // NewUserServiceServer creates a new user service server
-func NewUserServiceServer() pb.UserServiceServer {
-	return &userServiceServer{}
+func NewUserServiceServer(users *services.UserService) pb.UserServiceServer {
+	return &userServiceServer{
+		users: users,
+	}
 }
 
 // CreateUser creates a new user
-func (s *userServiceServer) CreateUser(_ context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
-	_, ok := users[req.GetUsername()]
-	if ok {
-		log.Error().Msg("user already exists")
-		return nil, nil
-	}
-
-	user := &pb.User{
-		Id:       uuid.New().String(),
-		Username: req.GetUsername(),
-		Email:    req.GetEmail(),
+func (s *userServiceServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	user, err := s.users.CreateUser(ctx, req.GetUsername(), req.GetEmail())
+	if err != nil {
+		return nil, toStatus(err)
 	}
-
-	users[req.GetUsername()] = user
 	return &pb.CreateUserResponse{
 		User: user,
 	}, nil
 }
 
 // GetUser gets a user
-func (s *userServiceServer) GetUser(_ context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
-	// Check if the request contains an ID, email, or username
-	if id := req.GetId(); id != "" {
-		for _, user := range users {
-			if user.GetId() == id {
-				return &pb.GetUserResponse{User: user}, nil
-			}
-		}
-		return nil, status.Errorf(codes.NotFound, "user not found with ID: %s", id)
+func (s *userServiceServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	user, err := s.users.GetUser(ctx, services.UserKey{
+		ID:       req.GetId(),
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+	}, req.GetShowDeleted())
+	if err != nil {
+		return nil, toStatus(err)
 	}
+	return &pb.GetUserResponse{User: user}, nil
+}
 
-	if email := req.GetEmail(); email != "" {
-		// Retrieve user by email
-		for _, user := range users {
-			if user.GetEmail() == email {
-				return &pb.GetUserResponse{User: user}, nil
-			}
+// BatchGetUsers gets several users, reporting the identifiers of the missing ones
+func (s *userServiceServer) BatchGetUsers(ctx context.Context, req *pb.BatchGetUsersRequest) (*pb.BatchGetUsersResponse, error) {
+	keys := make([]services.UserKey, len(req.GetIdentifiers()))
+	for i, identifier := range req.GetIdentifiers() {
+		keys[i] = services.UserKey{
+			ID:       identifier.GetId(),
+			Username: identifier.GetUsername(),
+			Email:    identifier.GetEmail(),
 		}
-		return nil, status.Errorf(codes.NotFound, "user not found with email: %s", email)
 	}
 
-	if username := req.GetUsername(); username != "" {
-		// Retrieve user by username
-		for _, user := range users {
-			if user.GetUsername() == username {
-				return &pb.GetUserResponse{User: user}, nil
-			}
-		}
-		return nil, status.Errorf(codes.NotFound, "user not found with username: %s", username)
+	users, err := s.users.BatchGetUsers(ctx, keys)
+	if err != nil {
+		return nil, toStatus(err)
 	}
 
-	return nil, status.Error(codes.InvalidArgument, "missing ID, email, or username in the request")
+	res := &pb.BatchGetUsersResponse{}
+	for i, user := range users {
+		if user == nil {
+			res.Missing = append(res.Missing, req.GetIdentifiers()[i])
+			continue
+		}
+		res.Users = append(res.Users, user)
+	}
+	return res, nil
 }
 
 // UpdateUser updates a user
-func (s *userServiceServer) UpdateUser(_ context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
-	username := req.GetUsername()
-	user, ok := users[username]
-	if !ok {
-		return nil, status.Errorf(codes.NotFound, "user not found: %s", username)
+func (s *userServiceServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	update := services.UserUpdate{
+		Key:      services.UserKey{ID: req.GetId()},
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+		ETag:     req.GetEtag(),
 	}
 
-	if email := req.GetEmail(); email != "" {
-		user.Email = email
+	switch {
+	case len(req.GetUpdateMask().GetPaths()) > 0:
+		if !req.GetUpdateMask().IsValid(&pb.User{}) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid update mask: %v", req.GetUpdateMask().GetPaths())
+		}
+		update.Paths = req.GetUpdateMask().GetPaths()
+		if req.GetId() == "" && contains(update.Paths, services.FieldUsername) {
+			return nil, status.Error(codes.InvalidArgument, "username can't be updated without id")
+		}
+	default:
+		// Without a mask, update the fields that are set on the request
+		if req.Username != nil {
+			update.Paths = append(update.Paths, services.FieldUsername)
+		}
+		if req.Email != nil {
+			update.Paths = append(update.Paths, services.FieldEmail)
+		}
 	}
 
-	user.UpdatedAt = timestamppb.Now()
+	if req.GetId() == "" {
+		// Legacy requests identify the user by username, which then can't be
+		// renamed: a username set without a mask is the key, not a new value
+		update.Key = services.UserKey{Username: req.GetUsername()}
+		update.Paths = without(update.Paths, services.FieldUsername)
+	}
 
-	users[username] = user
+	user, err := s.users.UpdateUser(ctx, update)
+	if err != nil {
+		return nil, toStatus(err)
+	}
 
 	return &pb.UpdateUserResponse{
 		User: user,
@@ -118,24 +135,381 @@ func (s *userServiceServer) UpdateUser(_ context.Context, req *pb.UpdateUserRequ
 }
 
 // DeleteUser deletes a user
-func (s *userServiceServer) DeleteUser(_ context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
-	user := users[req.GetId()]
-	delete(users, req.GetId())
-	log.Info().Msgf("user deleted %s", req.GetId())
+func (s *userServiceServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	user, err := s.users.DeleteUser(ctx, services.UserKey{
+		ID:       req.GetId(),
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+	}, req.GetEtag())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	log.Info().Msgf("user deleted %s", user.GetId())
 	return &pb.DeleteUserResponse{
 		User: user,
 	}, nil
 
 }
 
-// ListUsers lists all users
-func (s *userServiceServer) ListUsers(_ context.Context, _ *emptypb.Empty) (*pb.ListUsersResponse, error) {
-	var usersList []*pb.User
-	for _, user := range users {
-		usersList = append(usersList, user)
+// UndeleteUser restores a soft-deleted user
+func (s *userServiceServer) UndeleteUser(ctx context.Context, req *pb.UndeleteUserRequest) (*pb.UndeleteUserResponse, error) {
+	user, err := s.users.UndeleteUser(ctx, services.UserKey{
+		ID:       req.GetId(),
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	log.Info().Msgf("user undeleted %s", user.GetId())
+	return &pb.UndeleteUserResponse{
+		User: user,
+	}, nil
+}
+
+// ListUsers lists a page of users
+func (s *userServiceServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	page, err := s.users.ListUsers(ctx, services.ListUsersQuery{
+		PageSize:       int(req.GetPageSize()),
+		PageToken:      req.GetPageToken(),
+		OrderBy:        req.GetOrderBy(),
+		UsernamePrefix: req.GetUsernamePrefix(),
+		EmailPrefix:    req.GetEmailPrefix(),
+		ShowDeleted:    req.GetShowDeleted(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
 	}
 
 	return &pb.ListUsersResponse{
-		Users: usersList,
+		Users:         page.Users,
+		NextPageToken: page.NextPageToken,
+		TotalSize:     int32(page.TotalSize),
 	}, nil
 }
+
+// WatchUsers streams user changes until the client goes away
+func (s *userServiceServer) WatchUsers(req *pb.WatchUsersRequest, stream pb.UserService_WatchUsersServer) error {
+	sub, err := s.users.WatchUsers(stream.Context(), req.GetAfterRevision())
+	if err != nil {
+		return toStatus(err)
+	}
+	defer sub.Close()
+
+	// Tell the client where the watch starts, to resume from there if it breaks before the first event
+	if err := stream.SendHeader(metadata.Pairs(watchRevisionHeader, strconv.FormatUint(sub.Revision(), 10))); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-sub.Events():
+			if !ok {
+				return toStatus(sub.Err())
+			}
+			if err := stream.Send(toUserEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// BulkCreateUsers creates the users streamed by the client, one by one or all
+// together in all-or-nothing mode, and reports the outcome of each one
+func (s *userServiceServer) BulkCreateUsers(stream pb.UserService_BulkCreateUsersServer) error {
+	ctx := stream.Context()
+	res := &pb.BulkCreateUsersResponse{}
+
+	var (
+		allOrNothing bool
+		failed       bool
+		pending      []services.NewUser
+		pendingRes   []*pb.BulkCreateUsersResponse_Result
+	)
+	for index := 0; ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if index == 0 {
+			allOrNothing = req.GetAllOrNothing()
+		}
+		if index == maxBulkCreateUsers {
+			return status.Errorf(codes.InvalidArgument, "at most %d users can be created at once", maxBulkCreateUsers)
+		}
+
+		result := &pb.BulkCreateUsersResponse_Result{Index: int32(index)}
+		res.Results = append(res.Results, result)
+
+		// Stream messages don't go through the validation interceptor
+		if req.GetUser() == nil {
+			setBulkOutcome(result, nil, fmt.Errorf("%w: missing user", services.ErrInvalidArgument))
+			failed = true
+			continue
+		}
+		if err := validate.Validate(req.GetUser()); err != nil {
+			setBulkOutcome(result, nil, fmt.Errorf("%w: %s", services.ErrInvalidArgument, err.Error()))
+			failed = true
+			continue
+		}
+
+		newUser := services.NewUser{Username: req.GetUser().GetUsername(), Email: req.GetUser().GetEmail()}
+		if allOrNothing {
+			pending = append(pending, newUser)
+			pendingRes = append(pendingRes, result)
+			continue
+		}
+
+		user, err := s.users.CreateUser(ctx, newUser.Username, newUser.Email)
+		if !setBulkOutcome(result, user, err) {
+			return toStatus(err)
+		}
+	}
+
+	if allOrNothing && len(pending) > 0 {
+		var (
+			created []*pb.User
+			errs    []error
+		)
+		if !failed {
+			var err error
+			if created, errs, err = s.users.CreateUsers(ctx, pending); err != nil {
+				return toStatus(err)
+			}
+		}
+		for i, result := range pendingRes {
+			switch {
+			case created != nil:
+				setBulkOutcome(result, created[i], nil)
+			case errs != nil && errs[i] != nil:
+				setBulkOutcome(result, nil, errs[i])
+			default:
+				result.Status = pb.BulkCreateUsersResponse_Result_STATUS_ROLLED_BACK
+			}
+		}
+	}
+
+	for _, result := range res.Results {
+		switch result.Status {
+		case pb.BulkCreateUsersResponse_Result_STATUS_CREATED:
+			res.CreatedCount++
+		case pb.BulkCreateUsersResponse_Result_STATUS_ALREADY_EXISTS:
+			res.AlreadyExistsCount++
+		case pb.BulkCreateUsersResponse_Result_STATUS_INVALID:
+			res.InvalidCount++
+		}
+	}
+	return stream.SendAndClose(res)
+}
+
+// SyncUsers reconciles users with the desired users streamed by the client
+// and streams back the action taken for each one. Once the client is done
+// sending, the users it didn't stream are deleted in delete-missing mode.
+func (s *userServiceServer) SyncUsers(stream pb.UserService_SyncUsersServer) error {
+	ctx := stream.Context()
+
+	var (
+		dryRun        bool
+		deleteMissing bool
+		synced        = make(map[string]bool)
+		failed        int
+	)
+	for index := 0; ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if index == 0 {
+			dryRun = req.GetDryRun()
+			deleteMissing = req.GetDeleteMissing()
+		}
+
+		res := &pb.SyncUsersResponse{Index: proto.Int32(int32(index)), DryRun: dryRun}
+		// The user is desired even if the row is invalid, it must not be deleted as missing
+		if username := req.GetUser().GetUsername(); username != "" {
+			synced[username] = true
+		}
+		// Stream messages don't go through the validation interceptor
+		switch {
+		case req.GetUser() == nil:
+			res.Error = fmt.Errorf("%w: missing user", services.ErrInvalidArgument).Error()
+		default:
+			if err := validate.Validate(req.GetUser()); err != nil {
+				res.Error = fmt.Errorf("%w: %s", services.ErrInvalidArgument, err.Error()).Error()
+				break
+			}
+
+			action, user, err := s.users.SyncUser(ctx, services.NewUser{
+				Username: req.GetUser().GetUsername(),
+				Email:    req.GetUser().GetEmail(),
+			}, dryRun)
+			if err != nil && !isUserError(err) {
+				return toStatus(err)
+			}
+			res.Action = toSyncAction(action)
+			res.User = user
+			if err != nil {
+				res.Error = err.Error()
+			}
+		}
+		if res.Error != "" {
+			failed++
+		}
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+
+	if !deleteMissing {
+		return nil
+	}
+	if failed > 0 {
+		// The desired state is uncertain, deleting could remove users the client meant to keep
+		return stream.Send(&pb.SyncUsersResponse{
+			Error:  fmt.Errorf("%w: %d desired users failed, no missing user was deleted", services.ErrFailedPrecondition, failed).Error(),
+			DryRun: dryRun,
+		})
+	}
+	unsynced, err := s.users.UnsyncedUsers(ctx, synced)
+	if err != nil {
+		return toStatus(err)
+	}
+	for _, user := range unsynced {
+		res := &pb.SyncUsersResponse{Action: pb.SyncUsersResponse_ACTION_DELETE, User: user, DryRun: dryRun}
+		if !dryRun {
+			deleted, err := s.users.DeleteUser(ctx, services.UserKey{ID: user.GetId()}, user.GetEtag())
+			switch {
+			case errors.Is(err, services.ErrNotFound):
+				// Deleted concurrently, the desired state is reached anyway
+			case errors.Is(err, services.ErrAborted):
+				// Changed since it was listed, it is left to the next sync
+				res.Error = err.Error()
+			case err != nil:
+				return toStatus(err)
+			default:
+				res.User = deleted
+			}
+		}
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isUserError tells if err is about the user a request is for, rather than a
+// failure that should end the whole request
+func isUserError(err error) bool {
+	return errors.Is(err, services.ErrAlreadyExists) ||
+		errors.Is(err, services.ErrInvalidArgument) ||
+		errors.Is(err, services.ErrNotFound) ||
+		errors.Is(err, services.ErrFailedPrecondition)
+}
+
+// toSyncAction converts a service sync action to its protobuf enum
+func toSyncAction(action services.SyncAction) pb.SyncUsersResponse_Action {
+	switch action {
+	case services.SyncCreate:
+		return pb.SyncUsersResponse_ACTION_CREATE
+	case services.SyncUpdate:
+		return pb.SyncUsersResponse_ACTION_UPDATE
+	case services.SyncNoop:
+		return pb.SyncUsersResponse_ACTION_NOOP
+	default:
+		return pb.SyncUsersResponse_ACTION_UNSPECIFIED
+	}
+}
+
+// setBulkOutcome records the outcome of creating a user in result. It
+// returns false if err is unrelated to the user and should fail the whole request.
+func setBulkOutcome(result *pb.BulkCreateUsersResponse_Result, user *pb.User, err error) bool {
+	switch {
+	case err == nil:
+		result.Status = pb.BulkCreateUsersResponse_Result_STATUS_CREATED
+		result.User = user
+	case errors.Is(err, services.ErrAlreadyExists):
+		result.Status = pb.BulkCreateUsersResponse_Result_STATUS_ALREADY_EXISTS
+		result.Error = err.Error()
+	case errors.Is(err, services.ErrInvalidArgument):
+		result.Status = pb.BulkCreateUsersResponse_Result_STATUS_INVALID
+		result.Error = err.Error()
+	default:
+		return false
+	}
+	return true
+}
+
+// toUserEvent converts a service event to its protobuf message
+func toUserEvent(event services.UserEvent) *pb.UserEvent {
+	eventType := pb.UserEvent_TYPE_UNSPECIFIED
+	switch event.Type {
+	case services.EventCreated:
+		eventType = pb.UserEvent_TYPE_CREATED
+	case services.EventUpdated:
+		eventType = pb.UserEvent_TYPE_UPDATED
+	case services.EventDeleted:
+		eventType = pb.UserEvent_TYPE_DELETED
+	}
+	return &pb.UserEvent{
+		Type:     eventType,
+		User:     event.User,
+		Revision: event.Revision,
+	}
+}
+
+// contains tells if paths holds path
+func contains(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// without returns paths without path
+func without(paths []string, path string) []string {
+	var kept []string
+	for _, p := range paths {
+		if p != path {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// toStatus converts a service error into a gRPC status error
+func toStatus(err error) error {
+	var conflict *services.ConflictError
+	switch {
+	case errors.As(err, &conflict) && conflict.Deleted:
+		return apierrors.NewAlreadyExistsDeleted(err.Error(), conflict.Field)
+	case errors.As(err, &conflict):
+		return apierrors.NewAlreadyExists(err.Error(), conflict.Field)
+	case errors.Is(err, services.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, services.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, services.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, services.ErrOutOfRange):
+		return status.Error(codes.OutOfRange, err.Error())
+	case errors.Is(err, services.ErrSlowConsumer):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, services.ErrFailedPrecondition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, services.ErrAborted):
+		return status.Error(codes.Aborted, err.Error())
+	default:
+		log.Error().Err(err).Msg("user service error")
+		return status.Error(codes.Internal, "internal error")
+	}
+}
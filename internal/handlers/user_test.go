@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/internal/repositories"
+	"github.com/msharbaji/grpc-go-example/internal/services"
+	"github.com/msharbaji/grpc-go-example/pkg/apierrors"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"github.com/msharbaji/grpc-go-example/pkg/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentLoad hits the five user RPCs concurrently on the memory
+// store, run it with -race. The usernames and emails are drawn from a small
+// pool so calls keep conflicting with each other.
+func TestConcurrentLoad(t *testing.T) {
+	const (
+		workers = 16
+		calls   = 300
+		names   = 20
+	)
+	ctx := context.Background()
+	repo := repositories.NewMemoryUserRepository()
+	users, err := services.NewUserService(repo, services.SystemClock, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewUserServiceServer(users)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed))
+			for i := 0; i < calls; i++ {
+				username := fmt.Sprintf("user%d", rnd.Intn(names))
+				email := fmt.Sprintf("user%d@example.com", rnd.Intn(names))
+
+				var err error
+				switch rnd.Intn(5) {
+				case 0:
+					_, err = s.CreateUser(ctx, &pb.CreateUserRequest{Username: username, Email: email})
+				case 1:
+					_, err = s.GetUser(ctx, &pb.GetUserRequest{Username: &username})
+				case 2:
+					var res *pb.GetUserResponse
+					if res, err = s.GetUser(ctx, &pb.GetUserRequest{Username: &username}); err == nil {
+						_, err = s.UpdateUser(ctx, &pb.UpdateUserRequest{
+							Id:         res.GetUser().GetId(),
+							Email:      &email,
+							UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+						})
+					}
+				case 3:
+					_, err = s.DeleteUser(ctx, &pb.DeleteUserRequest{Email: &email})
+				case 4:
+					_, err = s.ListUsers(ctx, &pb.ListUsersRequest{PageSize: 10, ShowDeleted: rnd.Intn(2) == 0})
+				}
+				switch status.Code(err) {
+				case codes.OK, codes.NotFound, codes.AlreadyExists, codes.Aborted:
+				default:
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+			}
+		}(int64(w))
+	}
+	wg.Wait()
+
+	assertIndexesConsistent(t, repo)
+
+	// Purging removes the deleted users from the indexes too
+	if _, err := repo.Purge(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	assertIndexesConsistent(t, repo)
+}
+
+// assertIndexesConsistent checks that every stored user is found by its
+// username and email, and that the indexes hold no other usernames or emails
+func assertIndexesConsistent(t *testing.T, repo repositories.UserRepository) {
+	t.Helper()
+	ctx := context.Background()
+
+	stored, total, err := repo.List(ctx, repositories.ListOptions{Tenant: tenant.Default, ShowDeleted: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != len(stored) {
+		t.Fatalf("listed %d users out of %d", len(stored), total)
+	}
+
+	usernames := make(map[string]string)
+	emails := make(map[string]string)
+	for _, user := range stored {
+		if other, ok := usernames[user.GetUsername()]; ok {
+			t.Errorf("users %s and %s share username %s", other, user.GetId(), user.GetUsername())
+		}
+		if other, ok := emails[user.GetEmail()]; ok {
+			t.Errorf("users %s and %s share email %s", other, user.GetId(), user.GetEmail())
+		}
+		usernames[user.GetUsername()] = user.GetId()
+		emails[user.GetEmail()] = user.GetId()
+	}
+
+	for i := 0; i < 20; i++ {
+		username := fmt.Sprintf("user%d", i)
+		found, err := repo.GetByUsername(ctx, tenant.Default, username)
+		assertIndexed(t, "username", username, usernames[username], found, err)
+
+		email := fmt.Sprintf("user%d@example.com", i)
+		found, err = repo.GetByEmail(ctx, tenant.Default, email)
+		assertIndexed(t, "email", email, emails[email], found, err)
+	}
+}
+
+// assertIndexed checks that the index lookup of value found the user with wantID, or none when it is empty
+func assertIndexed(t *testing.T, field, value, wantID string, found *pb.User, err error) {
+	t.Helper()
+	switch {
+	case wantID == "" && err == nil:
+		t.Errorf("%s %s is indexed to user %s which isn't stored", field, value, found.GetId())
+	case wantID == "" && err != repositories.ErrUserNotFound:
+		t.Errorf("failed to get user by %s %s: %v", field, value, err)
+	case wantID != "" && err != nil:
+		t.Errorf("user %s isn't indexed by %s %s: %v", wantID, field, value, err)
+	case wantID != "" && found.GetId() != wantID:
+		t.Errorf("%s %s is indexed to user %s instead of %s", field, value, found.GetId(), wantID)
+	}
+}
+
+func TestUpdateUserWithoutID(t *testing.T) {
+	ctx := context.Background()
+	users, err := services.NewUserService(repositories.NewMemoryUserRepository(), services.SystemClock, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewUserServiceServer(users)
+	if _, err := s.CreateUser(ctx, &pb.CreateUserRequest{Username: "alice", Email: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	username, email := "alice", "alice@example.org"
+	_, err = s.UpdateUser(ctx, &pb.UpdateUserRequest{
+		Username:   &username,
+		Email:      &email,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"username", "email"}},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v updating the username without id, want InvalidArgument", err)
+	}
+
+	// Without a mask the username is the key of the legacy request
+	res, err := s.UpdateUser(ctx, &pb.UpdateUserRequest{Username: &username, Email: &email})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetUser().GetUsername() != username || res.GetUser().GetEmail() != email {
+		t.Errorf("got %v, want the email of alice updated", res.GetUser())
+	}
+}
+
+// TestConflictWithDeletedUser checks that reusing the username or email of a
+// soft-deleted user tells the client the user holding it is deleted
+func TestConflictWithDeletedUser(t *testing.T) {
+	ctx := context.Background()
+	users, err := services.NewUserService(repositories.NewMemoryUserRepository(), services.SystemClock, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewUserServiceServer(users)
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := s.CreateUser(ctx, &pb.CreateUserRequest{Username: name, Email: name + "@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	alice := "alice"
+	if _, err := s.DeleteUser(ctx, &pb.DeleteUserRequest{Username: &alice}); err != nil {
+		t.Fatal(err)
+	}
+	bob, err := s.GetUser(ctx, &pb.GetUserRequest{Username: proto.String("bob")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		call        func() error
+		field       string
+		wantDeleted bool
+	}{
+		{"create with the username of a deleted user", func() error {
+			_, err := s.CreateUser(ctx, &pb.CreateUserRequest{Username: "alice", Email: "alice@example.org"})
+			return err
+		}, "username", true},
+		{"create with the email of a deleted user", func() error {
+			_, err := s.CreateUser(ctx, &pb.CreateUserRequest{Username: "carol", Email: "alice@example.com"})
+			return err
+		}, "email", true},
+		{"update to the email of a deleted user", func() error {
+			_, err := s.UpdateUser(ctx, &pb.UpdateUserRequest{
+				Id:         bob.GetUser().GetId(),
+				Email:      proto.String("alice@example.com"),
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+			})
+			return err
+		}, "email", true},
+		{"create with the username of a live user", func() error {
+			_, err := s.CreateUser(ctx, &pb.CreateUserRequest{Username: "bob", Email: "bob@example.org"})
+			return err
+		}, "username", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call()
+			if status.Code(err) != codes.AlreadyExists {
+				t.Fatalf("got %v, want AlreadyExists", err)
+			}
+			if field, _ := apierrors.ConflictingField(err); field != tc.field {
+				t.Errorf("got conflicting field %q, want %q", field, tc.field)
+			}
+			if got := apierrors.ConflictsWithDeleted(err); got != tc.wantDeleted {
+				t.Errorf("got deleted %v, want %v", got, tc.wantDeleted)
+			}
+		})
+	}
+}
+
+// syncStream is a SyncUsers stream replaying reqs and recording the responses
+type syncStream struct {
+	grpc.ServerStream
+	reqs      []*pb.SyncUsersRequest
+	responses []*pb.SyncUsersResponse
+}
+
+func (s *syncStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *syncStream) Recv() (*pb.SyncUsersRequest, error) {
+	if len(s.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return req, nil
+}
+
+func (s *syncStream) Send(res *pb.SyncUsersResponse) error {
+	s.responses = append(s.responses, res)
+	return nil
+}
+
+func TestSyncUsersDeleteMissing(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		bobEmail    string
+		wantDeleted []string
+	}{
+		{name: "valid", bobEmail: "bob@example.org", wantDeleted: []string{"carol"}},
+		// bob's row is invalid: he must not be deleted as missing, nor anyone else
+		{name: "invalid row", bobEmail: "not an email"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			users, err := services.NewUserService(repositories.NewMemoryUserRepository(), services.SystemClock, []byte("secret"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := NewUserServiceServer(users)
+			for _, name := range []string{"alice", "bob", "carol"} {
+				if _, err := s.CreateUser(ctx, &pb.CreateUserRequest{Username: name, Email: name + "@example.com"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			stream := &syncStream{reqs: []*pb.SyncUsersRequest{
+				{User: &pb.CreateUserRequest{Username: "alice", Email: "alice@example.com"}, DeleteMissing: true},
+				{User: &pb.CreateUserRequest{Username: "bob", Email: test.bobEmail}},
+			}}
+			if err := s.SyncUsers(stream); err != nil {
+				t.Fatal(err)
+			}
+
+			var deleted []string
+			for _, res := range stream.responses {
+				if res.GetAction() == pb.SyncUsersResponse_ACTION_DELETE {
+					deleted = append(deleted, res.GetUser().GetUsername())
+				}
+			}
+			if fmt.Sprint(deleted) != fmt.Sprint(test.wantDeleted) {
+				t.Errorf("deleted %v, want %v", deleted, test.wantDeleted)
+			}
+			for _, name := range []string{"alice", "bob"} {
+				if _, err := s.GetUser(ctx, &pb.GetUserRequest{Username: &name}); err != nil {
+					t.Errorf("failed to get %s after the sync: %v", name, err)
+				}
+			}
+
+			if test.wantDeleted == nil {
+				last := stream.responses[len(stream.responses)-1]
+				if last.Index != nil || last.GetError() == "" {
+					t.Errorf("got last response %v, want one telling the deletions were skipped", last)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"context"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"google.golang.org/protobuf/proto"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryUserRepository stores users by ID, with unique indexes from
+// username and email to ID. All access is guarded by mu.
+type memoryUserRepository struct {
+	mu         sync.RWMutex
+	users      map[string]*pb.User
+	byUsername map[tenantKey]string
+	byEmail    map[tenantKey]string
+}
+
+// tenantKey is the key of a field that is unique per tenant
+type tenantKey struct {
+	tenant string
+	value  string
+}
+
+// NewMemoryUserRepository creates a new in-memory user repository seeded with the given users
+func NewMemoryUserRepository(users ...*pb.User) UserRepository {
+	r := &memoryUserRepository{
+		users:      make(map[string]*pb.User, len(users)),
+		byUsername: make(map[tenantKey]string, len(users)),
+		byEmail:    make(map[tenantKey]string, len(users)),
+	}
+	for _, user := range users {
+		r.index(clone(user))
+	}
+	return r
+}
+
+// Create stores a new user
+func (r *memoryUserRepository) Create(_ context.Context, user *pb.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.GetId()]; ok {
+		return &ConflictError{Field: "id"}
+	}
+	if err := r.conflicts(user); err != nil {
+		return err
+	}
+
+	r.index(clone(user))
+	return nil
+}
+
+// GetByID returns the user with the given ID
+func (r *memoryUserRepository) GetByID(_ context.Context, id string) (*pb.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.get(id)
+}
+
+// GetByUsername returns the user of tenant with the given username
+func (r *memoryUserRepository) GetByUsername(_ context.Context, tenant, username string) (*pb.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.get(r.byUsername[tenantKey{tenant, username}])
+}
+
+// GetByEmail returns the user of tenant with the given email
+func (r *memoryUserRepository) GetByEmail(_ context.Context, tenant, email string) (*pb.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.get(r.byEmail[tenantKey{tenant, email}])
+}
+
+// Update atomically applies fn to the user with the given ID and stores the result
+func (r *memoryUserRepository) Update(_ context.Context, id string, fn func(user *pb.User) error) (*pb.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	user := clone(current)
+	if err := fn(user); err != nil {
+		return nil, err
+	}
+	user.Id = id
+	if err := r.conflicts(user); err != nil {
+		return nil, err
+	}
+
+	r.unindex(current)
+	r.index(clone(user))
+	return user, nil
+}
+
+// List returns the page of users selected by opts and the number of users matching its filters
+func (r *memoryUserRepository) List(_ context.Context, opts ListOptions) ([]*pb.User, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*pb.User, 0, len(r.users))
+	for _, user := range r.users {
+		if user.GetTenant() != opts.Tenant || (user.GetDeletedAt() != nil && !opts.ShowDeleted) {
+			continue
+		}
+		if strings.HasPrefix(user.GetUsername(), opts.UsernamePrefix) && strings.HasPrefix(user.GetEmail(), opts.EmailPrefix) {
+			users = append(users, user)
+		}
+	}
+	before := func(a, b *pb.User) bool {
+		if opts.Descending {
+			return less(b, a, opts.OrderBy)
+		}
+		return less(a, b, opts.OrderBy)
+	}
+	sort.Slice(users, func(i, j int) bool { return before(users[i], users[j]) })
+
+	total := len(users)
+	if opts.After != nil {
+		after := opts.After.user()
+		users = users[sort.Search(len(users), func(i int) bool { return before(after, users[i]) }):]
+	}
+	if opts.Limit > 0 && opts.Limit < len(users) {
+		users = users[:opts.Limit]
+	}
+	for i, user := range users {
+		users[i] = clone(user)
+	}
+	return users, total, nil
+}
+
+// Purge removes the users soft-deleted before deletedBefore
+func (r *memoryUserRepository) Purge(_ context.Context, deletedBefore time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := 0
+	for _, user := range r.users {
+		if user.GetDeletedAt() != nil && user.GetDeletedAt().AsTime().Before(deletedBefore) {
+			r.unindex(user)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// get returns a copy of the user with the given ID. The caller must hold mu.
+func (r *memoryUserRepository) get(id string) (*pb.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return clone(user), nil
+}
+
+// conflicts returns a ConflictError if another user of its tenant already
+// holds the username or email of user. The caller must hold mu.
+func (r *memoryUserRepository) conflicts(user *pb.User) error {
+	if id, ok := r.byUsername[tenantKey{user.GetTenant(), user.GetUsername()}]; ok && id != user.GetId() {
+		return &ConflictError{Field: "username"}
+	}
+	if id, ok := r.byEmail[tenantKey{user.GetTenant(), user.GetEmail()}]; ok && id != user.GetId() {
+		return &ConflictError{Field: "email"}
+	}
+	return nil
+}
+
+// index adds the user to the primary storage and the secondary indexes.
+// The caller must hold mu.
+func (r *memoryUserRepository) index(user *pb.User) {
+	r.users[user.GetId()] = user
+	r.byUsername[tenantKey{user.GetTenant(), user.GetUsername()}] = user.GetId()
+	r.byEmail[tenantKey{user.GetTenant(), user.GetEmail()}] = user.GetId()
+}
+
+// unindex removes the user from the primary storage and the secondary
+// indexes. The caller must hold mu.
+func (r *memoryUserRepository) unindex(user *pb.User) {
+	delete(r.users, user.GetId())
+	delete(r.byUsername, tenantKey{user.GetTenant(), user.GetUsername()})
+	delete(r.byEmail, tenantKey{user.GetTenant(), user.GetEmail()})
+}
+
+// less orders users by the orderBy field then by ID
+func less(a, b *pb.User, orderBy string) bool {
+	switch orderBy {
+	case OrderByCreatedAt:
+		switch {
+		case a.GetCreatedAt() == nil && b.GetCreatedAt() != nil:
+			return true
+		case a.GetCreatedAt() != nil && b.GetCreatedAt() == nil:
+			return false
+		case !a.GetCreatedAt().AsTime().Equal(b.GetCreatedAt().AsTime()):
+			return a.GetCreatedAt().AsTime().Before(b.GetCreatedAt().AsTime())
+		}
+	default:
+		if a.GetUsername() != b.GetUsername() {
+			return a.GetUsername() < b.GetUsername()
+		}
+	}
+	return a.GetId() < b.GetId()
+}
+
+// clone returns a deep copy of the user so callers can't mutate stored state
+func clone(user *pb.User) *pb.User {
+	u, _ := proto.Clone(user).(*pb.User)
+	return u
+}
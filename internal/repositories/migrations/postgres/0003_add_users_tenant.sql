@@ -0,0 +1,7 @@
+ALTER TABLE users ADD COLUMN tenant TEXT NOT NULL DEFAULT 'default';
+
+ALTER TABLE users
+    DROP CONSTRAINT users_username_key,
+    DROP CONSTRAINT users_email_key,
+    ADD CONSTRAINT users_username_key UNIQUE (tenant, username),
+    ADD CONSTRAINT users_email_key UNIQUE (tenant, email);
@@ -0,0 +1,22 @@
+-- SQLite can't alter constraints, the table is rebuilt with usernames and
+-- emails unique per tenant
+CREATE TABLE users_new (
+    id         TEXT PRIMARY KEY,
+    tenant     TEXT NOT NULL DEFAULT 'default',
+    username   TEXT NOT NULL,
+    email      TEXT NOT NULL,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    CONSTRAINT users_username_key UNIQUE (tenant, username),
+    CONSTRAINT users_email_key UNIQUE (tenant, email)
+);
+
+INSERT INTO users_new (id, username, email, created_at, updated_at, deleted_at)
+SELECT id, username, email, created_at, updated_at, deleted_at FROM users;
+
+DROP TABLE users;
+
+ALTER TABLE users_new RENAME TO users;
+
+CREATE INDEX users_deleted_at_idx ON users (deleted_at);
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"time"
+)
+
+const (
+	// pgUniqueViolation is the SQLSTATE Postgres reports for unique constraint violations
+	pgUniqueViolation = "23505"
+	// pgMigrationsLock is the key of the advisory lock held while migrating
+	pgMigrationsLock = "7263766931"
+)
+
+var postgresDialect = dialect{
+	name:                 "postgres",
+	numberedPlaceholders: true,
+	noLimit:              "ALL",
+	forUpdate:            " FOR UPDATE",
+	lockMigrations:       "SELECT pg_advisory_lock(" + pgMigrationsLock + ")",
+	unlockMigrations:     "SELECT pg_advisory_unlock(" + pgMigrationsLock + ")",
+	uniqueViolation: func(err error) (string, bool) {
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != pgUniqueViolation {
+			return "", false
+		}
+		return pgErr.ConstraintName, true
+	},
+}
+
+// PoolConfig limits the connections a SQL user repository keeps to its database.
+// Zero values keep the database/sql defaults.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+func (c PoolConfig) apply(db *sql.DB) {
+	if c.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(c.MaxOpenConns)
+	}
+	if c.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	if c.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(c.ConnMaxLifetime)
+	}
+	if c.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+	}
+}
+
+// NewPostgresUserRepository connects to the Postgres database at dsn with a
+// connection pool limited by pool, applies the pending migrations and returns
+// a user repository backed by it
+func NewPostgresUserRepository(ctx context.Context, dsn string, pool PoolConfig) (UserRepository, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres database: %w", err)
+	}
+	pool.apply(db)
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to connect to postgres database: %w", err)
+	}
+
+	r, err := newSQLUserRepository(ctx, db, postgresDialect)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return r, nil
+}
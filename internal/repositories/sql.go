@@ -0,0 +1,360 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const userColumns = "id, tenant, username, email, created_at, updated_at, deleted_at"
+
+// dialect captures the differences between the SQL databases we support
+type dialect struct {
+	// name is also the directory of the dialect's migrations
+	name string
+	// numberedPlaceholders rewrites ? placeholders into $1, $2, ...
+	numberedPlaceholders bool
+	// noLimit is the LIMIT that returns all rows
+	noLimit string
+	// forUpdate is appended to a SELECT to lock the selected rows until the transaction ends
+	forUpdate string
+	// lockMigrations and unlockMigrations take and release a lock held by the
+	// connection, serializing the migrations of instances sharing the database.
+	// They are empty when the database needs none.
+	lockMigrations   string
+	unlockMigrations string
+	// uniqueViolation reports whether err was caused by a unique constraint,
+	// and if so a description of the constraint that names the column
+	uniqueViolation func(err error) (constraint string, ok bool)
+}
+
+// rebind rewrites the ? placeholders of query into the dialect's placeholder syntax
+func (d dialect) rebind(query string) string {
+	if !d.numberedPlaceholders {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// querier runs queries on a database or in a transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// sqlUserRepository is a UserRepository backed by a database/sql database.
+// Within InTx, tx is the running transaction and q runs queries in it.
+type sqlUserRepository struct {
+	db      *sql.DB
+	tx      *sql.Tx
+	q       querier
+	dialect dialect
+}
+
+func newSQLUserRepository(ctx context.Context, db *sql.DB, d dialect) (*sqlUserRepository, error) {
+	if err := migrate(ctx, db, d); err != nil {
+		return nil, err
+	}
+	return &sqlUserRepository{
+		db:      db,
+		q:       db,
+		dialect: d,
+	}, nil
+}
+
+// InTx calls fn with a repository whose writes are committed together if fn
+// returns nil, and rolled back otherwise
+func (r *sqlUserRepository) InTx(ctx context.Context, fn func(users UserRepository) error) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return fn(&sqlUserRepository{
+			db:      r.db,
+			tx:      tx,
+			q:       tx,
+			dialect: r.dialect,
+		})
+	})
+}
+
+// withTx calls fn in the running transaction, or in a new one committed if fn returns nil
+func (r *sqlUserRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return r.wrap("begin transaction", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return r.wrap("commit transaction", err)
+	}
+	return nil
+}
+
+// Create stores a new user
+func (r *sqlUserRepository) Create(ctx context.Context, user *pb.User) error {
+	_, err := r.q.ExecContext(ctx,
+		r.dialect.rebind("INSERT INTO users ("+userColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)"),
+		user.GetId(), user.GetTenant(), user.GetUsername(), user.GetEmail(), toNullTime(user.GetCreatedAt()), toNullTime(user.GetUpdatedAt()), toNullTime(user.GetDeletedAt()),
+	)
+	if err != nil {
+		return r.wrap("create user", err)
+	}
+	return nil
+}
+
+// GetByID returns the user with the given ID
+func (r *sqlUserRepository) GetByID(ctx context.Context, id string) (*pb.User, error) {
+	return r.getBy(ctx, "id = ?", id)
+}
+
+// GetByUsername returns the user of tenant with the given username
+func (r *sqlUserRepository) GetByUsername(ctx context.Context, tenant, username string) (*pb.User, error) {
+	return r.getBy(ctx, "tenant = ? AND username = ?", tenant, username)
+}
+
+// GetByEmail returns the user of tenant with the given email
+func (r *sqlUserRepository) GetByEmail(ctx context.Context, tenant, email string) (*pb.User, error) {
+	return r.getBy(ctx, "tenant = ? AND email = ?", tenant, email)
+}
+
+// Update atomically applies fn to the user with the given ID and stores the
+// result. The read and the write happen in a single transaction, with the row
+// locked for the duration when the dialect supports it.
+func (r *sqlUserRepository) Update(ctx context.Context, id string, fn func(user *pb.User) error) (*pb.User, error) {
+	var user *pb.User
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx, r.dialect.rebind("SELECT "+userColumns+" FROM users WHERE id = ?"+r.dialect.forUpdate), id)
+		current, err := scanUser(row)
+		if err != nil {
+			return r.wrap("update user", err)
+		}
+
+		if err := fn(current); err != nil {
+			return err
+		}
+		current.Id = id
+
+		if _, err := tx.ExecContext(ctx,
+			r.dialect.rebind("UPDATE users SET tenant = ?, username = ?, email = ?, created_at = ?, updated_at = ?, deleted_at = ? WHERE id = ?"),
+			current.GetTenant(), current.GetUsername(), current.GetEmail(), toNullTime(current.GetCreatedAt()), toNullTime(current.GetUpdatedAt()), toNullTime(current.GetDeletedAt()), id,
+		); err != nil {
+			return r.wrap("update user", err)
+		}
+		user = current
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// List returns the page of users selected by opts and the number of users matching its filters
+func (r *sqlUserRepository) List(ctx context.Context, opts ListOptions) ([]*pb.User, int, error) {
+	where := []string{"tenant = ?"}
+	args := []any{opts.Tenant}
+	if !opts.ShowDeleted {
+		where = append(where, "deleted_at IS NULL")
+	}
+	// substr rather than LIKE, which is case-insensitive in SQLite and needs escaping
+	if opts.UsernamePrefix != "" {
+		where = append(where, "substr(username, 1, ?) = ?")
+		args = append(args, utf8.RuneCountInString(opts.UsernamePrefix), opts.UsernamePrefix)
+	}
+	if opts.EmailPrefix != "" {
+		where = append(where, "substr(email, 1, ?) = ?")
+		args = append(args, utf8.RuneCountInString(opts.EmailPrefix), opts.EmailPrefix)
+	}
+	filter := " WHERE " + strings.Join(where, " AND ")
+
+	var total int
+	if err := r.q.QueryRowContext(ctx, r.dialect.rebind("SELECT COUNT(*) FROM users"+filter), args...).Scan(&total); err != nil {
+		return nil, 0, r.wrap("count users", err)
+	}
+
+	if opts.After != nil {
+		after, afterArgs := afterClause(opts)
+		filter += " AND " + after
+		args = append(args, afterArgs...)
+	}
+	query := "SELECT " + userColumns + " FROM users" + filter + " ORDER BY " + orderClause(opts)
+	if opts.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, opts.Limit)
+	} else {
+		// LIMIT -1 is SQLite for no limit, ALL is Postgres
+		query += " LIMIT " + r.dialect.noLimit
+	}
+
+	rows, err := r.q.QueryContext(ctx, r.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, 0, r.wrap("list users", err)
+	}
+	defer rows.Close()
+
+	var users []*pb.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, r.wrap("list users", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, r.wrap("list users", err)
+	}
+	return users, total, nil
+}
+
+// Purge removes the users soft-deleted before deletedBefore
+func (r *sqlUserRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	// Times are stored in UTC, SQLite compares them as text
+	res, err := r.q.ExecContext(ctx, r.dialect.rebind("DELETE FROM users WHERE deleted_at < ?"), deletedBefore.UTC())
+	if err != nil {
+		return 0, r.wrap("purge users", err)
+	}
+	purged, err := res.RowsAffected()
+	if err != nil {
+		return 0, r.wrap("purge users", err)
+	}
+	return int(purged), nil
+}
+
+// Close closes the underlying database
+func (r *sqlUserRepository) Close() error {
+	return r.db.Close()
+}
+
+// getBy returns the user matching the condition of the where clause
+func (r *sqlUserRepository) getBy(ctx context.Context, where string, args ...any) (*pb.User, error) {
+	row := r.q.QueryRowContext(ctx, r.dialect.rebind("SELECT "+userColumns+" FROM users WHERE "+where), args...)
+	user, err := scanUser(row)
+	if err != nil {
+		return nil, r.wrap("get user", err)
+	}
+	return user, nil
+}
+
+// wrap translates database errors into repository errors
+func (r *sqlUserRepository) wrap(op string, err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrUserNotFound
+	default:
+		if constraint, ok := r.dialect.uniqueViolation(err); ok {
+			return &ConflictError{Field: conflictField(constraint)}
+		}
+		return fmt.Errorf("failed to %s: %w", op, err)
+	}
+}
+
+// orderClause returns the ORDER BY clause of opts
+func orderClause(opts ListOptions) string {
+	if opts.OrderBy == OrderByCreatedAt {
+		if opts.Descending {
+			return "created_at DESC NULLS LAST, id DESC"
+		}
+		return "created_at ASC NULLS FIRST, id ASC"
+	}
+	if opts.Descending {
+		return "username DESC, id DESC"
+	}
+	return "username ASC, id ASC"
+}
+
+// afterClause returns the condition selecting the users ordered after
+// opts.After by orderClause, and its arguments
+func afterClause(opts ListOptions) (string, []any) {
+	cmp := ">"
+	if opts.Descending {
+		cmp = "<"
+	}
+	after := opts.After
+	if opts.OrderBy != OrderByCreatedAt {
+		return "(username " + cmp + " ? OR (username = ? AND id " + cmp + " ?))", []any{after.Username, after.Username, after.ID}
+	}
+
+	// Users without a creation time come first, or last when descending
+	switch {
+	case after.CreatedAt == nil && opts.Descending:
+		return "(created_at IS NULL AND id < ?)", []any{after.ID}
+	case after.CreatedAt == nil:
+		return "(created_at IS NOT NULL OR id > ?)", []any{after.ID}
+	case opts.Descending:
+		// Times are stored in UTC, SQLite compares them as text
+		createdAt := after.CreatedAt.UTC()
+		return "(created_at IS NULL OR created_at < ? OR (created_at = ? AND id < ?))", []any{createdAt, createdAt, after.ID}
+	default:
+		createdAt := after.CreatedAt.UTC()
+		return "(created_at > ? OR (created_at = ? AND id > ?))", []any{createdAt, createdAt, after.ID}
+	}
+}
+
+// conflictField returns the user field guarded by the violated unique constraint
+func conflictField(constraint string) string {
+	switch {
+	case strings.Contains(constraint, "username"):
+		return "username"
+	case strings.Contains(constraint, "email"):
+		return "email"
+	default:
+		return "id"
+	}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(s scanner) (*pb.User, error) {
+	var (
+		user                            pb.User
+		createdAt, updatedAt, deletedAt sql.NullTime
+	)
+	if err := s.Scan(&user.Id, &user.Tenant, &user.Username, &user.Email, &createdAt, &updatedAt, &deletedAt); err != nil {
+		return nil, err
+	}
+	user.CreatedAt = fromNullTime(createdAt)
+	user.UpdatedAt = fromNullTime(updatedAt)
+	user.DeletedAt = fromNullTime(deletedAt)
+	return &user, nil
+}
+
+func toNullTime(ts *timestamppb.Timestamp) sql.NullTime {
+	if ts == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: ts.AsTime(), Valid: true}
+}
+
+func fromNullTime(t sql.NullTime) *timestamppb.Timestamp {
+	if !t.Valid {
+		return nil
+	}
+	return timestamppb.New(t.Time)
+}
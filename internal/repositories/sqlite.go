@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var sqliteDialect = dialect{
+	name:    "sqlite",
+	noLimit: "-1",
+	uniqueViolation: func(err error) (string, bool) {
+		var sqliteErr *sqlite.Error
+		if !errors.As(err, &sqliteErr) {
+			return "", false
+		}
+		if sqliteErr.Code() != sqlite3.SQLITE_CONSTRAINT_UNIQUE && sqliteErr.Code() != sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY {
+			return "", false
+		}
+		// e.g. "UNIQUE constraint failed: users.email"
+		return sqliteErr.Error(), true
+	},
+}
+
+// NewSQLiteUserRepository opens the SQLite database at dsn, applies the
+// pending migrations and returns a user repository backed by it
+func NewSQLiteUserRepository(ctx context.Context, dsn string) (UserRepository, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	// SQLite allows a single writer, serialize access instead of failing with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	r, err := newSQLUserRepository(ctx, db, sqliteDialect)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrUserAlreadyExists = errors.New("user already exists")
+)
+
+// ConflictError is returned when a write would break the uniqueness of a user field.
+// It matches ErrUserAlreadyExists with errors.Is.
+type ConflictError struct {
+	// Field is the conflicting user field, "id", "username" or "email"
+	Field string
+}
+
+func (e *ConflictError) Error() string {
+	return "user already exists with the same " + e.Field
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrUserAlreadyExists
+}
+
+// UserRepository is the storage backend for users. Usernames and emails are
+// unique per tenant, IDs are unique across tenants. Soft-deleted users, those
+// with a DeletedAt time, are stored and returned like the others until purged.
+type UserRepository interface {
+	// Create stores a new user.
+	Create(ctx context.Context, user *pb.User) error
+
+	// GetByID returns the user with the given ID.
+	GetByID(ctx context.Context, id string) (*pb.User, error)
+
+	// GetByUsername returns the user of tenant with the given username.
+	GetByUsername(ctx context.Context, tenant, username string) (*pb.User, error)
+
+	// GetByEmail returns the user of tenant with the given email.
+	GetByEmail(ctx context.Context, tenant, email string) (*pb.User, error)
+
+	// Update atomically applies fn to the user with the given ID and stores
+	// the result. If fn returns an error nothing is stored and the error is returned.
+	Update(ctx context.Context, id string, fn func(user *pb.User) error) (*pb.User, error)
+
+	// List returns the page of users selected by opts, and the number of
+	// users matching its filters across all pages.
+	List(ctx context.Context, opts ListOptions) ([]*pb.User, int, error)
+
+	// Purge removes the users of every tenant soft-deleted before deletedBefore
+	// and returns how many were removed.
+	Purge(ctx context.Context, deletedBefore time.Time) (int, error)
+}
+
+// Orderings accepted by ListOptions.OrderBy
+const (
+	OrderByUsername  = "username"
+	OrderByCreatedAt = "created_at"
+)
+
+// ListOptions selects, orders and paginates users. Ties are broken by ID so
+// the order is stable. Users without a creation time sort first.
+type ListOptions struct {
+	// Tenant only selects the users of that tenant
+	Tenant string
+	// OrderBy is OrderByUsername or OrderByCreatedAt
+	OrderBy    string
+	Descending bool
+	// UsernamePrefix and EmailPrefix only select users whose field starts with them
+	UsernamePrefix string
+	EmailPrefix    string
+	// ShowDeleted also selects soft-deleted users
+	ShowDeleted bool
+	// After, when set, only selects the users ordered after that position, so
+	// pages stay consistent when users are added or removed between them
+	After *ListCursor
+	// Limit caps the number of users returned, zero means no limit
+	Limit int
+}
+
+// ListCursor is the position of a user in a list: the key it is ordered by,
+// the field of ListOptions.OrderBy, and its ID breaking ties
+type ListCursor struct {
+	Username string
+	// CreatedAt is nil for users without a creation time
+	CreatedAt *time.Time
+	ID        string
+}
+
+// CursorOf returns the position of user in lists
+func CursorOf(user *pb.User) *ListCursor {
+	cursor := &ListCursor{Username: user.GetUsername(), ID: user.GetId()}
+	if user.GetCreatedAt() != nil {
+		createdAt := user.GetCreatedAt().AsTime()
+		cursor.CreatedAt = &createdAt
+	}
+	return cursor
+}
+
+// user returns a user at the position of the cursor, for comparisons
+func (c *ListCursor) user() *pb.User {
+	user := &pb.User{Id: c.ID, Username: c.Username}
+	if c.CreatedAt != nil {
+		user.CreatedAt = timestamppb.New(*c.CreatedAt)
+	}
+	return user
+}
+
+// Transactor is implemented by repositories that can run several writes atomically
+type Transactor interface {
+	// InTx calls fn with a repository whose writes are committed together if
+	// fn returns nil, and discarded otherwise. fn's error is returned.
+	InTx(ctx context.Context, fn func(users UserRepository) error) error
+}
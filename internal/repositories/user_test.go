@@ -0,0 +1,343 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testUserRepository runs the tests every UserRepository must pass against
+// the empty repositories returned by newRepo
+func testUserRepository(t *testing.T, newRepo func(t *testing.T) UserRepository) {
+	t.Run("Create", func(t *testing.T) { testCreate(t, newRepo(t)) })
+	t.Run("Conflicts", func(t *testing.T) { testConflicts(t, newRepo(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newRepo(t)) })
+	t.Run("ConcurrentUpdates", func(t *testing.T) { testConcurrentUpdates(t, newRepo(t)) })
+	t.Run("List", func(t *testing.T) { testList(t, newRepo(t)) })
+	t.Run("Purge", func(t *testing.T) { testPurge(t, newRepo(t)) })
+	t.Run("InTx", func(t *testing.T) {
+		repo := newRepo(t)
+		if _, ok := repo.(Transactor); !ok {
+			t.Skip("repository has no transactions")
+		}
+		testInTx(t, repo)
+	})
+}
+
+func TestMemoryUserRepository(t *testing.T) {
+	testUserRepository(t, func(t *testing.T) UserRepository {
+		return NewMemoryUserRepository()
+	})
+}
+
+func TestSQLiteUserRepository(t *testing.T) {
+	testUserRepository(t, func(t *testing.T) UserRepository {
+		repo, err := NewSQLiteUserRepository(context.Background(), "file:"+t.TempDir()+"/users.db")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = repo.(*sqlUserRepository).Close() })
+		return repo
+	})
+}
+
+// newUser returns a user of tenant named after name
+func newUser(tenant, name string) *pb.User {
+	return &pb.User{
+		Id:        tenant + "-" + name,
+		Tenant:    tenant,
+		Username:  name,
+		Email:     name + "@example.com",
+		CreatedAt: timestamppb.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}
+}
+
+func mustCreate(t *testing.T, repo UserRepository, users ...*pb.User) {
+	t.Helper()
+	for _, user := range users {
+		if err := repo.Create(context.Background(), user); err != nil {
+			t.Fatalf("failed to create user %s: %v", user.GetId(), err)
+		}
+	}
+}
+
+func testCreate(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	alice := newUser("acme", "alice")
+	mustCreate(t, repo, alice)
+
+	for name, get := range map[string]func() (*pb.User, error){
+		"id":       func() (*pb.User, error) { return repo.GetByID(ctx, alice.GetId()) },
+		"username": func() (*pb.User, error) { return repo.GetByUsername(ctx, "acme", "alice") },
+		"email":    func() (*pb.User, error) { return repo.GetByEmail(ctx, "acme", "alice@example.com") },
+	} {
+		got, err := get()
+		if err != nil {
+			t.Fatalf("failed to get user by %s: %v", name, err)
+		}
+		if got.GetId() != alice.GetId() || got.GetTenant() != "acme" || !got.GetCreatedAt().AsTime().Equal(alice.GetCreatedAt().AsTime()) {
+			t.Errorf("got %v by %s, want %v", got, name, alice)
+		}
+	}
+
+	// Usernames and emails are unique per tenant only
+	if _, err := repo.GetByUsername(ctx, "other", "alice"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("got %v from another tenant, want ErrUserNotFound", err)
+	}
+	mustCreate(t, repo, newUser("other", "alice"))
+}
+
+func testConflicts(t *testing.T, repo UserRepository) {
+	alice := newUser("acme", "alice")
+	mustCreate(t, repo, alice)
+
+	sameID := newUser("acme", "bob")
+	sameID.Id = alice.GetId()
+	sameUsername := newUser("acme", "carol")
+	sameUsername.Username = "alice"
+	sameEmail := newUser("acme", "dave")
+	sameEmail.Email = alice.GetEmail()
+
+	for field, user := range map[string]*pb.User{"id": sameID, "username": sameUsername, "email": sameEmail} {
+		err := repo.Create(context.Background(), user)
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) || conflict.Field != field || !errors.Is(err, ErrUserAlreadyExists) {
+			t.Errorf("got %v creating a user with the same %s, want a ConflictError on it", err, field)
+		}
+	}
+}
+
+func testUpdate(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	alice, bob := newUser("acme", "alice"), newUser("acme", "bob")
+	mustCreate(t, repo, alice, bob)
+
+	updated, err := repo.Update(ctx, alice.GetId(), func(user *pb.User) error {
+		user.Email = "alice@example.org"
+		return nil
+	})
+	if err != nil || updated.GetEmail() != "alice@example.org" {
+		t.Fatalf("got %v, %v, want the updated user", updated, err)
+	}
+	if got, err := repo.GetByEmail(ctx, "acme", "alice@example.org"); err != nil || got.GetId() != alice.GetId() {
+		t.Errorf("got %v, %v by the new email, want the updated user", got, err)
+	}
+	if _, err := repo.GetByEmail(ctx, "acme", "alice@example.com"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("got %v by the old email, want ErrUserNotFound", err)
+	}
+
+	failure := errors.New("failure")
+	if _, err := repo.Update(ctx, alice.GetId(), func(user *pb.User) error {
+		user.Username = "changed"
+		return failure
+	}); !errors.Is(err, failure) {
+		t.Errorf("got %v, want the error of fn", err)
+	}
+	if got, _ := repo.GetByID(ctx, alice.GetId()); got.GetUsername() != "alice" {
+		t.Errorf("failed update was stored: %v", got)
+	}
+
+	var conflict *ConflictError
+	if _, err := repo.Update(ctx, alice.GetId(), func(user *pb.User) error {
+		user.Username = "bob"
+		return nil
+	}); !errors.As(err, &conflict) || conflict.Field != "username" {
+		t.Errorf("got %v taking the username of another user, want a ConflictError on it", err)
+	}
+
+	if _, err := repo.Update(ctx, "missing", func(user *pb.User) error { return nil }); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("got %v updating a missing user, want ErrUserNotFound", err)
+	}
+}
+
+// testConcurrentUpdates checks that updates are read-modify-writes that don't
+// overwrite each other: each one increments a counter kept in the email
+func testConcurrentUpdates(t *testing.T, repo UserRepository) {
+	const updates = 20
+	ctx := context.Background()
+	alice := newUser("acme", "alice")
+	alice.Email = "0@example.com"
+	mustCreate(t, repo, alice)
+
+	var wg sync.WaitGroup
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.Update(ctx, alice.GetId(), func(user *pb.User) error {
+				var n int
+				if _, err := fmt.Sscanf(user.GetEmail(), "%d@example.com", &n); err != nil {
+					return err
+				}
+				user.Email = strconv.Itoa(n+1) + "@example.com"
+				return nil
+			}); err != nil {
+				t.Errorf("failed to update: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := repo.GetByID(ctx, alice.GetId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("%d@example.com", updates); got.GetEmail() != want {
+		t.Errorf("got email %s after %d updates, want %s", got.GetEmail(), updates, want)
+	}
+}
+
+func testList(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	users := []*pb.User{newUser("acme", "carol"), newUser("acme", "alice"), newUser("acme", "bob"), newUser("acme", "albert")}
+	users[0].CreatedAt = nil
+	users[3].DeletedAt = timestamppb.Now()
+	mustCreate(t, repo, users...)
+	mustCreate(t, repo, newUser("other", "anna"))
+
+	for _, test := range []struct {
+		name      string
+		opts      ListOptions
+		wantIDs   []string
+		wantTotal int
+	}{
+		{
+			name:      "by username",
+			opts:      ListOptions{Tenant: "acme", OrderBy: OrderByUsername},
+			wantIDs:   []string{"acme-alice", "acme-bob", "acme-carol"},
+			wantTotal: 3,
+		},
+		{
+			name:      "descending page",
+			opts:      ListOptions{Tenant: "acme", OrderBy: OrderByUsername, Descending: true, After: CursorOf(users[0]), Limit: 1},
+			wantIDs:   []string{"acme-bob"},
+			wantTotal: 3,
+		},
+		{
+			name:      "after a user no longer listed",
+			opts:      ListOptions{Tenant: "acme", OrderBy: OrderByUsername, After: &ListCursor{Username: "b", ID: "removed"}},
+			wantIDs:   []string{"acme-bob", "acme-carol"},
+			wantTotal: 3,
+		},
+		{
+			name:      "by creation time, after a user without one",
+			opts:      ListOptions{Tenant: "acme", OrderBy: OrderByCreatedAt, After: CursorOf(users[0])},
+			wantIDs:   []string{"acme-alice", "acme-bob"},
+			wantTotal: 3,
+		},
+		{
+			name:      "by creation time, after a tie",
+			opts:      ListOptions{Tenant: "acme", OrderBy: OrderByCreatedAt, After: CursorOf(users[1])},
+			wantIDs:   []string{"acme-bob"},
+			wantTotal: 3,
+		},
+		{
+			name:      "by descending creation time, after a tie",
+			opts:      ListOptions{Tenant: "acme", OrderBy: OrderByCreatedAt, Descending: true, After: CursorOf(users[2])},
+			wantIDs:   []string{"acme-alice", "acme-carol"},
+			wantTotal: 3,
+		},
+		{
+			name:      "by descending creation time, after a user without one",
+			opts:      ListOptions{Tenant: "acme", OrderBy: OrderByCreatedAt, Descending: true, After: CursorOf(users[0])},
+			wantIDs:   []string{},
+			wantTotal: 3,
+		},
+		{
+			name:      "by creation time, then ID",
+			opts:      ListOptions{Tenant: "acme", OrderBy: OrderByCreatedAt},
+			wantIDs:   []string{"acme-carol", "acme-alice", "acme-bob"},
+			wantTotal: 3,
+		},
+		{
+			name:      "prefixes",
+			opts:      ListOptions{Tenant: "acme", OrderBy: OrderByUsername, UsernamePrefix: "al", EmailPrefix: "ali"},
+			wantIDs:   []string{"acme-alice"},
+			wantTotal: 1,
+		},
+		{
+			name:      "deleted",
+			opts:      ListOptions{Tenant: "acme", OrderBy: OrderByUsername, UsernamePrefix: "al", ShowDeleted: true},
+			wantIDs:   []string{"acme-albert", "acme-alice"},
+			wantTotal: 2,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, total, err := repo.List(ctx, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := userIDs(got); fmt.Sprint(ids) != fmt.Sprint(test.wantIDs) || total != test.wantTotal {
+				t.Errorf("got %v of %d, want %v of %d", ids, total, test.wantIDs, test.wantTotal)
+			}
+		})
+	}
+}
+
+func testPurge(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	old, recent, alive := newUser("acme", "old"), newUser("other", "recent"), newUser("acme", "alive")
+	old.DeletedAt = timestamppb.New(time.Now().Add(-2 * time.Hour))
+	recent.DeletedAt = timestamppb.New(time.Now().Add(-time.Minute))
+	mustCreate(t, repo, old, recent, alive)
+
+	purged, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+	if err != nil || purged != 1 {
+		t.Fatalf("got %d, %v, want 1 purged user", purged, err)
+	}
+	if _, err := repo.GetByUsername(ctx, "acme", "old"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("got %v for the purged user, want ErrUserNotFound", err)
+	}
+	// Its username is free again
+	mustCreate(t, repo, &pb.User{Id: "new-old", Tenant: "acme", Username: "old", Email: "old@example.com"})
+	for _, user := range []*pb.User{recent, alive} {
+		if _, err := repo.GetByID(ctx, user.GetId()); err != nil {
+			t.Errorf("user %s was purged: %v", user.GetId(), err)
+		}
+	}
+}
+
+func testInTx(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	transactor := repo.(Transactor)
+
+	if err := transactor.InTx(ctx, func(users UserRepository) error {
+		mustCreate(t, users, newUser("acme", "alice"))
+		_, err := users.Update(ctx, "acme-alice", func(user *pb.User) error {
+			user.Email = "alice@example.org"
+			return nil
+		})
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := repo.GetByID(ctx, "acme-alice"); err != nil || got.GetEmail() != "alice@example.org" {
+		t.Errorf("got %v, %v, want the committed user", got, err)
+	}
+
+	// A conflict in the middle of the transaction rolls back the writes before it
+	err := transactor.InTx(ctx, func(users UserRepository) error {
+		mustCreate(t, users, newUser("acme", "bob"))
+		return users.Create(ctx, newUser("acme", "alice"))
+	})
+	if !errors.Is(err, ErrUserAlreadyExists) {
+		t.Fatalf("got %v, want the conflict", err)
+	}
+	if _, err := repo.GetByID(ctx, "acme-bob"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("got %v for a user created in a rolled back transaction, want ErrUserNotFound", err)
+	}
+}
+
+func userIDs(users []*pb.User) []string {
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.GetId()
+	}
+	return ids
+}
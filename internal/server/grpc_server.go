@@ -4,37 +4,140 @@ import (
 	"errors"
 	"fmt"
 	handlers2 "github.com/msharbaji/grpc-go-example/internal/handlers"
+	"github.com/msharbaji/grpc-go-example/internal/services"
 	"github.com/msharbaji/grpc-go-example/pkg/middleware"
 	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"github.com/msharbaji/grpc-go-example/pkg/secrets"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"net"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
+// AuthConfig configures how callers are authenticated and what they can access
+type AuthConfig struct {
+	// Secrets provides the secrets of HMAC key IDs
+	Secrets secrets.Provider
+	// Tenants gives HMAC key IDs the tenant they act in, tenant.Default when they have none
+	Tenants middleware.TenantProvider
+	// AdminKeys lists the HMAC key IDs that can act in any tenant
+	AdminKeys []string
+	// Roles gives HMAC key IDs their role, middleware.RoleViewer when they have none
+	Roles middleware.RoleProvider
+	// RejectLegacySignatures only accepts requests signed with the canonical
+	// scheme, once every client has migrated to it
+	RejectLegacySignatures bool
+	// LegacySignaturesCutoff is when legacy signatures stop being accepted
+	// even without RejectLegacySignatures, never when zero
+	LegacySignaturesCutoff time.Time
+	// ClockSkew is how far the timestamp of a request may be from the server
+	// clock, middleware.DefaultClockSkew when zero
+	ClockSkew time.Duration
+	// RequireSignedStreamMessages rejects the streams whose client doesn't
+	// sign each message, see middleware.SignStreamMessages
+	RequireSignedStreamMessages bool
+	// PublicMethods lists the methods anyone can call, without authentication,
+	// as full method names or service wildcards like /grpc.health.v1.Health/*.
+	// Every other method requires authentication.
+	PublicMethods []string
+}
+
+// DefaultPublicMethods are the health checks and server reflection, for load
+// balancers and tools like grpcurl
+var DefaultPublicMethods = []string{
+	"/grpc.health.v1.Health/*",
+	"/grpc.reflection.v1.ServerReflection/*",
+	"/grpc.reflection.v1alpha.ServerReflection/*",
+}
+
+// methodPermissions are the permissions required by each method, the methods
+// missing from it can't be called
+var methodPermissions = map[string][]middleware.Permission{
+	pb.VersionService_GetVersion_FullMethodName:   {middleware.PermissionReadVersion},
+	pb.UserService_GetUser_FullMethodName:         {middleware.PermissionReadUsers},
+	pb.UserService_BatchGetUsers_FullMethodName:   {middleware.PermissionReadUsers},
+	pb.UserService_ListUsers_FullMethodName:       {middleware.PermissionReadUsers},
+	pb.UserService_WatchUsers_FullMethodName:      {middleware.PermissionReadUsers},
+	pb.UserService_CreateUser_FullMethodName:      {middleware.PermissionWriteUsers},
+	pb.UserService_UpdateUser_FullMethodName:      {middleware.PermissionWriteUsers},
+	pb.UserService_BulkCreateUsers_FullMethodName: {middleware.PermissionWriteUsers},
+	pb.UserService_DeleteUser_FullMethodName:      {middleware.PermissionDeleteUsers},
+	pb.UserService_UndeleteUser_FullMethodName:    {middleware.PermissionDeleteUsers},
+	// SyncUsers may delete the users missing from the stream
+	pb.UserService_SyncUsers_FullMethodName: {middleware.PermissionWriteUsers, middleware.PermissionDeleteUsers},
+}
+
 type Grpc struct {
 	address string
 	server  *grpc.Server
+	health  *health.Server
 }
 
-// NewGrpcServer creates a new grpc server
-func NewGrpcServer(port string, secrets map[string]string) (*Grpc, error) {
+// NewGrpcServer creates a new grpc server. Mutating user calls with an
+// idempotency key are replayed for idempotencyWindow, zero disables it.
+func NewGrpcServer(port string, auth AuthConfig, users *services.UserService, idempotencyWindow time.Duration) (*Grpc, error) {
+	public, err := middleware.NewMethodMatcher(auth.PublicMethods...)
+	if err != nil {
+		return nil, err
+	}
+
+	var authOpts []middleware.ServerAuthOption
+	if auth.RejectLegacySignatures {
+		authOpts = append(authOpts, middleware.RejectLegacySignatures())
+	}
+	if !auth.LegacySignaturesCutoff.IsZero() {
+		authOpts = append(authOpts, middleware.RejectLegacySignaturesFrom(auth.LegacySignaturesCutoff))
+	}
+	if auth.ClockSkew > 0 {
+		authOpts = append(authOpts, middleware.WithClockSkew(auth.ClockSkew))
+	}
+	if auth.RequireSignedStreamMessages {
+		authOpts = append(authOpts, middleware.RequireSignedStreamMessages())
+	}
+	interceptors := []grpc.UnaryServerInterceptor{
+		middleware.NewServerBypassInterceptor(public,
+			middleware.NewServerAuthInterceptor(auth.Secrets, authOpts...),
+			middleware.NewServerAuthorizationInterceptor(auth.Roles, methodPermissions),
+			middleware.NewServerTenantInterceptor(auth.Tenants, auth.AdminKeys),
+		),
+		middleware.NewServerValidationInterceptor(),
+	}
+	if idempotencyWindow > 0 {
+		interceptors = append(interceptors, middleware.NewServerIdempotencyInterceptor(idempotencyWindow,
+			pb.UserService_CreateUser_FullMethodName,
+			pb.UserService_UpdateUser_FullMethodName,
+			pb.UserService_DeleteUser_FullMethodName,
+		))
+	}
+
 	opts := []grpc.ServerOption{
-		grpc.UnaryInterceptor(middleware.NewServerAuthInterceptor(secrets)),
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.ChainStreamInterceptor(
+			middleware.NewServerStreamBypassInterceptor(public,
+				middleware.NewServerStreamAuthInterceptor(auth.Secrets, authOpts...),
+				middleware.NewServerStreamAuthorizationInterceptor(auth.Roles, methodPermissions),
+				middleware.NewServerStreamTenantInterceptor(auth.Tenants, auth.AdminKeys),
+			),
+		),
 		grpc.Creds(insecure.NewCredentials()),
 	}
 	s := &Grpc{
 		address: fmt.Sprintf(":%s", port),
 		server:  grpc.NewServer(opts...),
+		health:  health.NewServer(),
 	}
 
 	pb.RegisterVersionServiceServer(s.server, handlers2.NewVersionServiceServer())
-	pb.RegisterUserServiceServer(s.server, handlers2.NewUserServiceServer())
+	pb.RegisterUserServiceServer(s.server, handlers2.NewUserServiceServer(users))
 
+	grpc_health_v1.RegisterHealthServer(s.server, s.health)
 	reflection.Register(s.server)
 	return s, nil
 }
@@ -56,6 +159,8 @@ func (s *Grpc) Start() {
 
 // Stop stops the grpc server
 func (s *Grpc) Stop() error {
+	// Load balancers stop sending calls while the running ones complete
+	s.health.Shutdown()
 	s.server.GracefulStop()
 	return nil
 }
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"github.com/msharbaji/grpc-go-example/internal/repositories"
+	"github.com/msharbaji/grpc-go-example/internal/services"
+	"github.com/msharbaji/grpc-go-example/pkg/middleware"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"github.com/msharbaji/grpc-go-example/pkg/secrets"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+)
+
+// authorize signs a call of method with keyID and runs it through the auth
+// and authorization interceptors the server chains, with roles
+func authorize(t *testing.T, roles middleware.Roles, keyID, method string) error {
+	t.Helper()
+	req := &pb.GetVersionRequest{}
+
+	var md metadata.MD
+	sign := middleware.NewClientAuthInterceptor(keyID, "secret")
+	err := sign(context.Background(), method, req, nil, nil, func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authenticate := middleware.NewServerAuthInterceptor(secrets.Static{keyID: "secret"})
+	authorization := middleware.NewServerAuthorizationInterceptor(roles, methodPermissions)
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	_, err = authenticate(metadata.NewIncomingContext(context.Background(), md), req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return authorization(ctx, req, info, func(context.Context, interface{}) (interface{}, error) {
+			return nil, nil
+		})
+	})
+	return err
+}
+
+func TestMethodPermissions(t *testing.T) {
+	roles := middleware.Roles{
+		"viewer-key": middleware.RoleViewer,
+		"editor-key": middleware.RoleEditor,
+		"owner-key":  middleware.RoleOwner,
+	}
+	// The keys allowed to call each method, a key without a role is a viewer
+	allowed := map[string][]string{
+		pb.VersionService_GetVersion_FullMethodName:   {"viewer-key", "editor-key", "owner-key", "unlisted-key"},
+		pb.UserService_GetUser_FullMethodName:         {"viewer-key", "editor-key", "owner-key", "unlisted-key"},
+		pb.UserService_BatchGetUsers_FullMethodName:   {"viewer-key", "editor-key", "owner-key", "unlisted-key"},
+		pb.UserService_ListUsers_FullMethodName:       {"viewer-key", "editor-key", "owner-key", "unlisted-key"},
+		pb.UserService_WatchUsers_FullMethodName:      {"viewer-key", "editor-key", "owner-key", "unlisted-key"},
+		pb.UserService_CreateUser_FullMethodName:      {"editor-key", "owner-key"},
+		pb.UserService_UpdateUser_FullMethodName:      {"editor-key", "owner-key"},
+		pb.UserService_BulkCreateUsers_FullMethodName: {"editor-key", "owner-key"},
+		pb.UserService_DeleteUser_FullMethodName:      {"owner-key"},
+		pb.UserService_UndeleteUser_FullMethodName:    {"owner-key"},
+		pb.UserService_SyncUsers_FullMethodName:       {"owner-key"},
+		// Methods without permissions can't be called
+		"/api.proto.v1.UserService/Unknown": {},
+	}
+
+	// Every method served needs a row, so a new one isn't left unreviewed
+	for _, desc := range []grpc.ServiceDesc{pb.VersionService_ServiceDesc, pb.UserService_ServiceDesc} {
+		for _, m := range desc.Methods {
+			if _, ok := allowed["/"+desc.ServiceName+"/"+m.MethodName]; !ok {
+				t.Errorf("method %s/%s has no row", desc.ServiceName, m.MethodName)
+			}
+		}
+		for _, s := range desc.Streams {
+			if _, ok := allowed["/"+desc.ServiceName+"/"+s.StreamName]; !ok {
+				t.Errorf("method %s/%s has no row", desc.ServiceName, s.StreamName)
+			}
+		}
+	}
+
+	for method, keys := range allowed {
+		for _, keyID := range []string{"viewer-key", "editor-key", "owner-key", "unlisted-key"} {
+			want := codes.PermissionDenied
+			for _, k := range keys {
+				if k == keyID {
+					want = codes.OK
+				}
+			}
+			if got := status.Code(authorize(t, roles, keyID, method)); got != want {
+				t.Errorf("%s calling %s: got %v, want %v", keyID, method, got, want)
+			}
+		}
+	}
+}
+
+// TestPublicMethods calls the server without authentication: the health
+// checks and server reflection answer, the other methods don't
+func TestPublicMethods(t *testing.T) {
+	users, err := services.NewUserService(repositories.NewMemoryUserRepository(), services.SystemClock, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewGrpcServer("0", AuthConfig{
+		Secrets:       secrets.Static{"key-a": "secret"},
+		Roles:         middleware.Roles{},
+		Tenants:       middleware.Tenants{},
+		PublicMethods: DefaultPublicMethods,
+	}, users, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = s.server.Serve(listener) }()
+	defer s.server.Stop()
+
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	health, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil || health.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("got %v, %v checking the health, want SERVING", health, err)
+	}
+
+	for name, list := range map[string]func() (int, error){
+		"v1": func() (int, error) {
+			stream, err := grpc_reflection_v1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+			if err != nil {
+				return 0, err
+			}
+			if err := stream.Send(&grpc_reflection_v1.ServerReflectionRequest{MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{}}); err != nil {
+				return 0, err
+			}
+			res, err := stream.Recv()
+			return len(res.GetListServicesResponse().GetService()), err
+		},
+		"v1alpha": func() (int, error) {
+			stream, err := grpc_reflection_v1alpha.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+			if err != nil {
+				return 0, err
+			}
+			if err := stream.Send(&grpc_reflection_v1alpha.ServerReflectionRequest{MessageRequest: &grpc_reflection_v1alpha.ServerReflectionRequest_ListServices{}}); err != nil {
+				return 0, err
+			}
+			res, err := stream.Recv()
+			return len(res.GetListServicesResponse().GetService()), err
+		},
+	} {
+		if services, err := list(); err != nil || services == 0 {
+			t.Errorf("got %d services, %v listing services with reflection %s, want the services of the server", services, err, name)
+		}
+	}
+
+	if _, err := pb.NewVersionServiceClient(conn).GetVersion(ctx, &pb.GetVersionRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v getting the version without authentication, want the missing signature error", err)
+	}
+	if _, err := pb.NewUserServiceClient(conn).ListUsers(ctx, &pb.ListUsersRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v listing users without authentication, want the missing signature error", err)
+	}
+}
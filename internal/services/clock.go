@@ -0,0 +1,22 @@
+package services
+
+import (
+	"time"
+)
+
+// Clock tells the current time. Services take it as a dependency so tests can
+// make timestamps deterministic.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface
+type ClockFunc func() time.Time
+
+// Now returns f()
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock backed by time.Now
+var SystemClock Clock = ClockFunc(time.Now)
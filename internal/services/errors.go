@@ -0,0 +1,42 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain errors returned by the services. They are wrapped with context about
+// the failing operation, compare them with errors.Is.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrInvalidArgument    = errors.New("invalid argument")
+	ErrOutOfRange         = errors.New("out of range")
+	ErrFailedPrecondition = errors.New("failed precondition")
+	ErrAborted            = errors.New("aborted")
+)
+
+// ConflictError is returned when a user field that must be unique is already
+// taken by another user. It matches ErrAlreadyExists with errors.Is.
+type ConflictError struct {
+	Field string
+	Value string
+	// Deleted is set when the user holding the value is soft-deleted, it keeps
+	// the value until it is purged
+	Deleted bool
+}
+
+func (e *ConflictError) Error() string {
+	msg := fmt.Sprintf("user already exists with the same %s", e.Field)
+	if e.Value != "" {
+		msg = fmt.Sprintf("user already exists with %s: %s", e.Field, e.Value)
+	}
+	if e.Deleted {
+		msg += " (deleted, undelete it or wait until it is purged)"
+	}
+	return msg
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrAlreadyExists
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// etag returns the etag of user, a digest of every field but the etag itself.
+// Writes always change the update time, so they always change the etag.
+func etag(user *pb.User) string {
+	u, _ := proto.Clone(user).(*pb.User)
+	u.Etag = ""
+	data, _ := proto.MarshalOptions{Deterministic: true}.Marshal(u)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+// withETag sets the etag of user and returns it
+func withETag(user *pb.User) *pb.User {
+	if user != nil {
+		user.Etag = etag(user)
+	}
+	return user
+}
+
+// checkETag fails with ErrAborted if want is set and isn't the etag of user
+func checkETag(user *pb.User, want string) error {
+	if want != "" && want != etag(user) {
+		return fmt.Errorf("%w: user %s changed since it was read", ErrAborted, user.GetId())
+	}
+	return nil
+}
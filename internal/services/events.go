@@ -0,0 +1,235 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"google.golang.org/protobuf/proto"
+	"sync"
+)
+
+// EventType tells how a user changed
+type EventType int
+
+const (
+	EventCreated EventType = iota + 1
+	EventUpdated
+	EventDeleted
+)
+
+const (
+	// eventHistorySize is the number of past events kept to resume watches
+	eventHistorySize = 1024
+	// subscriberBuffer is the number of events a watcher may lag behind before being dropped
+	subscriberBuffer = 256
+)
+
+// ErrSlowConsumer ends the subscriptions of watchers that can't keep up with the changes
+var ErrSlowConsumer = errors.New("watcher is too slow")
+
+// UserEvent is a change to a user
+type UserEvent struct {
+	Type EventType
+	// User is the user after the change, or as it was before being deleted
+	User *pb.User
+	// Revision increases with every change, see eventBroker
+	Revision uint64
+}
+
+// Subscription delivers the events of the users of a tenant to a watcher
+type Subscription struct {
+	tenant   string
+	revision uint64
+	events   chan UserEvent
+	broker   *eventBroker
+	err      error
+}
+
+// Revision returns the revision the subscription starts after, watches
+// broken before their first event resume from it
+func (s *Subscription) Revision() uint64 {
+	return s.revision
+}
+
+// Events returns the events of the subscription. It is closed when the
+// subscription ends, Err then tells why.
+func (s *Subscription) Events() <-chan UserEvent {
+	return s.events
+}
+
+// Err returns why the subscription ended, ErrSlowConsumer when the watcher
+// didn't keep up. It must only be called once Events is closed.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Close ends the subscription
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s, nil)
+}
+
+// eventBroker assigns revisions to user events, keeps the latest ones to
+// resume watches and fans them out to subscriptions. Publishing never blocks:
+// subscriptions that fall behind are ended with ErrSlowConsumer.
+//
+// Writes reserve their revision while they hold the lock of the user they
+// change, so the revisions of a user follow the order of its writes, and
+// events are delivered in revision order once every earlier write has
+// published or canceled its revision.
+//
+// The upper 32 bits of revisions are the epoch of the broker, random for
+// each run of the server, so resuming from a revision of a previous run fails
+// instead of silently missing the changes made since.
+type eventBroker struct {
+	mu    sync.Mutex
+	epoch uint64
+	// reserved is the last reserved revision, delivered the last one whose
+	// event, and the events of every revision before it, were delivered
+	reserved  uint64
+	delivered uint64
+	// pending holds the outcome of the revisions after delivered, nil until
+	// their write is done and then the event, empty when canceled
+	pending map[uint64]*UserEvent
+	history []UserEvent
+	// retainedAfter is the revision after which history holds every event
+	retainedAfter uint64
+	subscribers   map[*Subscription]struct{}
+}
+
+func newEventBroker() (*eventBroker, error) {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate event epoch: %w", err)
+	}
+	// Never zero, so no revision is zero either
+	epoch := uint64(binary.BigEndian.Uint32(b[:])|1) << 32
+	return &eventBroker{
+		epoch:         epoch,
+		delivered:     epoch,
+		reserved:      epoch,
+		retainedAfter: epoch,
+		pending:       make(map[uint64]*UserEvent),
+		subscribers:   make(map[*Subscription]struct{}),
+	}, nil
+}
+
+// reserve returns the revision of a write in progress. The write must then
+// publish or cancel it, the events of later revisions wait for it.
+func (b *eventBroker) reserve() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reserved++
+	b.pending[b.reserved] = nil
+	return b.reserved
+}
+
+// publish records the change made by the write of revision and delivers it
+// to the subscriptions, with the events of the following writes already done
+func (b *eventBroker) publish(revision uint64, eventType EventType, user *pb.User) {
+	// Subscribers share the event, keep it from being changed by the caller
+	user, _ = proto.Clone(user).(*pb.User)
+	b.done(revision, &UserEvent{Type: eventType, User: user, Revision: revision})
+}
+
+// cancel releases the revision of a write that failed, a zero revision is ignored
+func (b *eventBroker) cancel(revision uint64) {
+	if revision != 0 {
+		b.done(revision, &UserEvent{})
+	}
+}
+
+func (b *eventBroker) done(revision uint64, event *UserEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending[revision] = event
+	for {
+		next, ok := b.pending[b.delivered+1]
+		if !ok || next == nil {
+			return
+		}
+		delete(b.pending, b.delivered+1)
+		b.delivered++
+		if next.User != nil {
+			b.deliver(*next)
+		}
+	}
+}
+
+// deliver retains event and sends it to the subscriptions. The caller must hold mu.
+func (b *eventBroker) deliver(event UserEvent) {
+	if len(b.history) == eventHistorySize {
+		b.retainedAfter = b.history[0].Revision
+		b.history = append(b.history[:0], b.history[1:]...)
+	}
+	b.history = append(b.history, event)
+
+	for sub := range b.subscribers {
+		if sub.tenant != event.User.GetTenant() {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(sub, ErrSlowConsumer)
+		}
+	}
+}
+
+// subscribe starts a subscription receiving the retained events of the
+// users of tenant after afterRevision then every new one. Zero only receives
+// new events.
+func (b *eventBroker) subscribe(tenant string, afterRevision uint64) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []UserEvent
+	switch {
+	case afterRevision == 0:
+		afterRevision = b.delivered
+	case afterRevision&^(1<<32-1) != b.epoch:
+		return nil, fmt.Errorf("%w: revision %d is from a previous run of the server", ErrOutOfRange, afterRevision)
+	case afterRevision > b.delivered:
+		return nil, fmt.Errorf("%w: revision %d is ahead of the latest revision %d", ErrOutOfRange, afterRevision, b.delivered)
+	case afterRevision < b.retainedAfter:
+		return nil, fmt.Errorf("%w: revision %d is no longer retained", ErrOutOfRange, afterRevision)
+	default:
+		for _, event := range b.history {
+			if event.Revision > afterRevision && event.User.GetTenant() == tenant {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	sub := &Subscription{
+		tenant:   tenant,
+		revision: afterRevision,
+		events:   make(chan UserEvent, len(replay)+subscriberBuffer),
+		broker:   b,
+	}
+	for _, event := range replay {
+		sub.events <- event
+	}
+	b.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+func (b *eventBroker) unsubscribe(sub *Subscription, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(sub, err)
+}
+
+// remove ends the subscription with err. The caller must hold mu.
+func (b *eventBroker) remove(sub *Subscription, err error) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	sub.err = err
+	close(sub.events)
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"sync"
+	"testing"
+)
+
+func newTestBroker(t *testing.T) *eventBroker {
+	t.Helper()
+	b, err := newEventBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// receive returns the events of sub already delivered
+func receive(sub *Subscription) []UserEvent {
+	var events []UserEvent
+	for {
+		select {
+		case event := <-sub.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestEventsDeliveredInRevisionOrder(t *testing.T) {
+	b := newTestBroker(t)
+	sub, err := b.subscribe("acme", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	alice := &pb.User{Id: "alice", Tenant: "acme"}
+
+	first, second, third := b.reserve(), b.reserve(), b.reserve()
+	// The later writes are done first, they wait for the first one
+	b.publish(third, EventDeleted, alice)
+	b.cancel(second)
+	if events := receive(sub); len(events) != 0 {
+		t.Fatalf("got %v before the first write is done", events)
+	}
+	b.publish(first, EventCreated, alice)
+
+	events := receive(sub)
+	if len(events) != 2 || events[0].Revision != first || events[1].Revision != third {
+		t.Fatalf("got %v, want the events of revisions %d and %d in order", events, first, third)
+	}
+	if events[0].Type != EventCreated || events[1].Type != EventDeleted {
+		t.Errorf("got %v, want the creation then the deletion", events)
+	}
+}
+
+func TestSubscribeRevisions(t *testing.T) {
+	b := newTestBroker(t)
+	alice := &pb.User{Id: "alice", Tenant: "acme"}
+
+	// A watch broken before any event resumes from where it started
+	sub, err := b.subscribe("acme", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := sub.Revision()
+	sub.Close()
+	b.publish(b.reserve(), EventCreated, alice)
+	b.publish(b.reserve(), EventCreated, &pb.User{Id: "bob", Tenant: "other"})
+	b.publish(b.reserve(), EventUpdated, alice)
+
+	sub, err = b.subscribe("acme", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	if events := receive(sub); len(events) != 2 || events[0].Type != EventCreated || events[1].Type != EventUpdated {
+		t.Errorf("got %v resuming from the start revision, want the two events of the tenant", events)
+	}
+
+	for name, revision := range map[string]uint64{
+		"ahead":            b.delivered + 1,
+		"previous run":     (b.epoch ^ 1<<32) + 1,
+		"before the epoch": 1,
+	} {
+		if _, err := b.subscribe("acme", revision); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("got %v resuming from a revision %s, want ErrOutOfRange", err, name)
+		}
+	}
+}
+
+func TestSubscribeExpiredRevision(t *testing.T) {
+	b := newTestBroker(t)
+	alice := &pb.User{Id: "alice", Tenant: "acme"}
+	first := b.reserve()
+	b.publish(first, EventCreated, alice)
+	for i := 0; i < eventHistorySize; i++ {
+		b.publish(b.reserve(), EventUpdated, alice)
+	}
+
+	if _, err := b.subscribe("acme", first-1); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("got %v resuming from before the retained events, want ErrOutOfRange", err)
+	}
+	sub, err := b.subscribe("acme", first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	if events := receive(sub); len(events) != eventHistorySize {
+		t.Errorf("got %d events resuming from the oldest retained one, want %d", len(events), eventHistorySize)
+	}
+}
+
+// TestWatchOrderMatchesWrites updates a user concurrently: the events must
+// follow the order of the writes, which stamp increasing update times
+func TestWatchOrderMatchesWrites(t *testing.T) {
+	const updates = 200
+	ctx := context.Background()
+	s := newTestService(t, SystemClock)
+	user, err := s.CreateUser(ctx, "alice", "alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := s.WatchUsers(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			email := fmt.Sprintf("alice%d@example.com", i)
+			if _, err := s.UpdateUser(ctx, UserUpdate{Key: UserKey{ID: user.GetId()}, Email: email, Paths: []string{FieldEmail}}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	events := receive(sub)
+	if len(events) != updates {
+		t.Fatalf("got %d events, want %d", len(events), updates)
+	}
+	for i := 1; i < len(events); i++ {
+		if !events[i].User.GetUpdatedAt().AsTime().After(events[i-1].User.GetUpdatedAt().AsTime()) {
+			t.Fatalf("event %d of revision %d is older than the event before it", i, events[i].Revision)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// pageTokens signs and verifies the opaque page tokens returned by list
+// operations, so clients can't forge or alter them.
+type pageTokens struct {
+	key []byte
+}
+
+// pageCursor is the content of a page token: the position of the last user
+// of the page, the next page starts after it. Query fingerprints the list
+// request the token was issued for, tokens can't be reused with another one.
+type pageCursor struct {
+	Username  string     `json:"u,omitempty"`
+	CreatedAt *time.Time `json:"c,omitempty"`
+	ID        string     `json:"i"`
+	Query     string     `json:"q"`
+}
+
+// newPageTokens creates page tokens signed with key, or with a random key when it's empty
+func newPageTokens(key []byte) (*pageTokens, error) {
+	if len(key) == 0 {
+		key = make([]byte, sha256.Size)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate page token key: %w", err)
+		}
+	}
+	return &pageTokens{key: key}, nil
+}
+
+// encode returns the signed token of cursor
+func (p *pageTokens) encode(cursor pageCursor) string {
+	payload, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(p.sign(payload))
+}
+
+// decode verifies token and returns its cursor
+func (p *pageTokens) decode(token string) (pageCursor, error) {
+	var cursor pageCursor
+
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return cursor, fmt.Errorf("%w: malformed page token", ErrInvalidArgument)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return cursor, fmt.Errorf("%w: malformed page token", ErrInvalidArgument)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, p.sign(payload)) {
+		return cursor, fmt.Errorf("%w: invalid page token", ErrInvalidArgument)
+	}
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return cursor, fmt.Errorf("%w: malformed page token", ErrInvalidArgument)
+	}
+	return cursor, nil
+}
+
+func (p *pageTokens) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
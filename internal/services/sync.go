@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/internal/repositories"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// SyncAction is what reconciling a user did, or would do in dry run mode
+type SyncAction int
+
+const (
+	SyncCreate SyncAction = iota + 1
+	SyncUpdate
+	SyncNoop
+)
+
+// SyncUser reconciles the user whose username is desired.Username with
+// desired: it is created when missing and its email is updated when it
+// differs, a soft-deleted user fails with ErrFailedPrecondition. In dry run
+// mode nothing is changed and the returned user is the one that would
+// result, without ID when it would be created.
+func (s *UserService) SyncUser(ctx context.Context, desired NewUser, dryRun bool) (SyncAction, *pb.User, error) {
+	if desired.Username == "" {
+		return 0, nil, fmt.Errorf("%w: missing username", ErrInvalidArgument)
+	}
+	if desired.Email == "" {
+		return 0, nil, fmt.Errorf("%w: missing email", ErrInvalidArgument)
+	}
+
+	current, err := s.users.GetByUsername(ctx, tenantOf(ctx), desired.Username)
+	switch {
+	case errors.Is(err, repositories.ErrUserNotFound):
+		if !dryRun {
+			user, err := s.CreateUser(ctx, desired.Username, desired.Email)
+			return SyncCreate, user, err
+		}
+		if err := s.ensureAvailable(ctx, FieldEmail, desired.Email, s.users.GetByEmail); err != nil {
+			return SyncCreate, nil, err
+		}
+		return SyncCreate, &pb.User{Username: desired.Username, Email: desired.Email}, nil
+	case err != nil:
+		return 0, nil, translate(err)
+	case current.GetDeletedAt() != nil:
+		return 0, nil, fmt.Errorf("%w: user %s is deleted, undelete it first", ErrFailedPrecondition, desired.Username)
+	case current.GetEmail() == desired.Email:
+		return SyncNoop, withETag(current), nil
+	}
+
+	if !dryRun {
+		user, err := s.UpdateUser(ctx, UserUpdate{
+			Key:   UserKey{ID: current.GetId()},
+			Email: desired.Email,
+			Paths: []string{FieldEmail},
+		})
+		return SyncUpdate, user, err
+	}
+	if err := s.ensureAvailable(ctx, FieldEmail, desired.Email, s.users.GetByEmail); err != nil {
+		return SyncUpdate, nil, err
+	}
+	planned, _ := proto.Clone(current).(*pb.User)
+	planned.Email = desired.Email
+	// The etag is only known once written
+	planned.Etag = ""
+	return SyncUpdate, planned, nil
+}
+
+// UnsyncedUsers returns the users of the tenant of ctx whose username is not
+// in synced, ordered by username
+func (s *UserService) UnsyncedUsers(ctx context.Context, synced map[string]bool) ([]*pb.User, error) {
+	users, _, err := s.users.List(ctx, repositories.ListOptions{Tenant: tenantOf(ctx), OrderBy: repositories.OrderByUsername})
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	var unsynced []*pb.User
+	for _, user := range users {
+		if !synced[user.GetUsername()] {
+			unsynced = append(unsynced, withETag(user))
+		}
+	}
+	return unsynced, nil
+}
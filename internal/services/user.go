@@ -0,0 +1,580 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/msharbaji/grpc-go-example/internal/repositories"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"github.com/msharbaji/grpc-go-example/pkg/tenant"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strings"
+	"time"
+)
+
+// UserKey identifies a user by one of its unique fields. When several fields
+// are set, ID takes precedence over Email, and Email over Username.
+type UserKey struct {
+	ID       string
+	Username string
+	Email    string
+}
+
+// NewUser holds the fields of a user to create
+type NewUser struct {
+	Username string
+	Email    string
+}
+
+// Updatable user fields, as named in UserUpdate.Paths
+const (
+	FieldUsername = "username"
+	FieldEmail    = "email"
+)
+
+// UserUpdate describes changes to the user identified by Key
+type UserUpdate struct {
+	Key UserKey
+	// Username and Email hold the new values of the fields listed in Paths
+	Username string
+	Email    string
+	// Paths lists the fields to update, FieldUsername and/or FieldEmail
+	Paths []string
+	// ETag, when set, fails the update with ErrAborted if the user's etag changed
+	ETag string
+}
+
+// MaxBatchGetUsers caps the number of users of a BatchGetUsers call
+const MaxBatchGetUsers = 100
+
+// Page sizes of ListUsers
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 1000
+)
+
+// ListUsersQuery selects a page of users
+type ListUsersQuery struct {
+	// PageSize caps the number of users of the page, DefaultPageSize when zero
+	// and at most MaxPageSize
+	PageSize int
+	// PageToken is the NextPageToken of the previous page
+	PageToken string
+	// OrderBy is "username" or "created_at", optionally followed by " desc"
+	OrderBy        string
+	UsernamePrefix string
+	EmailPrefix    string
+	// ShowDeleted also lists soft-deleted users
+	ShowDeleted bool
+}
+
+// UserPage is a page of users returned by ListUsers
+type UserPage struct {
+	Users []*pb.User
+	// NextPageToken fetches the next page, empty on the last page
+	NextPageToken string
+	// TotalSize is the number of users matching the query across all pages
+	TotalSize int
+}
+
+// UserService holds the business rules for managing users. Every operation
+// acts in the tenant of its context, see tenant.FromContext, and only sees
+// the users of that tenant.
+type UserService struct {
+	users      repositories.UserRepository
+	clock      Clock
+	pageTokens *pageTokens
+	events     *eventBroker
+}
+
+// NewUserService creates a new user service storing users in the given
+// repository and stamping them with times read from clock. List page tokens
+// are signed with pageTokenKey, a random key is used when it's empty.
+func NewUserService(users repositories.UserRepository, clock Clock, pageTokenKey []byte) (*UserService, error) {
+	pageTokens, err := newPageTokens(pageTokenKey)
+	if err != nil {
+		return nil, err
+	}
+	events, err := newEventBroker()
+	if err != nil {
+		return nil, err
+	}
+	return &UserService{
+		users:      users,
+		clock:      clock,
+		pageTokens: pageTokens,
+		events:     events,
+	}, nil
+}
+
+// CreateUser creates a new user with a generated ID.
+// Usernames and emails must be unique, a taken one fails with a *ConflictError.
+func (s *UserService) CreateUser(ctx context.Context, username, email string) (*pb.User, error) {
+	user, revision, err := s.createUser(ctx, s.users, username, email)
+	if err != nil {
+		return nil, err
+	}
+	s.events.publish(revision, EventCreated, user)
+	return user, nil
+}
+
+// CreateUsers creates every user in a single transaction, so either all of
+// them are created or none is. errs holds why each user couldn't be created,
+// or nil. If any is not nil, the transaction is rolled back and created is nil.
+// The returned error reports failures unrelated to a specific user, it is
+// ErrFailedPrecondition when the repository doesn't support transactions.
+func (s *UserService) CreateUsers(ctx context.Context, users []NewUser) (created []*pb.User, errs []error, err error) {
+	transactor, ok := s.users.(repositories.Transactor)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: the user store does not support transactions", ErrFailedPrecondition)
+	}
+
+	errRollback := errors.New("rollback")
+	var revisions []uint64
+	err = transactor.InTx(ctx, func(tx repositories.UserRepository) error {
+		created = make([]*pb.User, len(users))
+		errs = make([]error, len(users))
+		failed := false
+		for i, u := range users {
+			user, revision, err := s.createUser(ctx, tx, u.Username, u.Email)
+			revisions = append(revisions, revision)
+			switch {
+			case errors.Is(err, ErrInvalidArgument), errors.Is(err, ErrAlreadyExists):
+				errs[i] = err
+				failed = true
+			case err != nil:
+				return err
+			default:
+				created[i] = user
+			}
+		}
+		if failed {
+			return errRollback
+		}
+		return nil
+	})
+	if err != nil {
+		for _, revision := range revisions {
+			s.events.cancel(revision)
+		}
+	}
+	if errors.Is(err, errRollback) {
+		return nil, errs, nil
+	}
+	if err != nil {
+		return nil, nil, translate(err)
+	}
+
+	for i, user := range created {
+		s.events.publish(revisions[i], EventCreated, user)
+	}
+	return created, errs, nil
+}
+
+// createUser validates and stores a new user in users, and returns the
+// revision of its creation event, zero when it fails
+func (s *UserService) createUser(ctx context.Context, users repositories.UserRepository, username, email string) (*pb.User, uint64, error) {
+	if username == "" {
+		return nil, 0, fmt.Errorf("%w: missing username", ErrInvalidArgument)
+	}
+	if email == "" {
+		return nil, 0, fmt.Errorf("%w: missing email", ErrInvalidArgument)
+	}
+
+	if err := s.ensureAvailable(ctx, "username", username, users.GetByUsername); err != nil {
+		return nil, 0, err
+	}
+	if err := s.ensureAvailable(ctx, "email", email, users.GetByEmail); err != nil {
+		return nil, 0, err
+	}
+
+	now := timestamppb.New(s.now())
+	user := &pb.User{
+		Id:        uuid.New().String(),
+		Tenant:    tenantOf(ctx),
+		Username:  username,
+		Email:     email,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	// Nothing can change the user before it is created, its later events get later revisions
+	revision := s.events.reserve()
+	if err := users.Create(ctx, user); err != nil {
+		s.events.cancel(revision)
+		return nil, 0, translate(err)
+	}
+	return withETag(user), revision, nil
+}
+
+// GetUser returns the user identified by key. Soft-deleted users are only
+// returned with showDeleted.
+func (s *UserService) GetUser(ctx context.Context, key UserKey, showDeleted bool) (*pb.User, error) {
+	var (
+		user     *pb.User
+		err      error
+		notFound error
+		t        = tenantOf(ctx)
+	)
+
+	switch {
+	case key.ID != "":
+		user, err = s.users.GetByID(ctx, key.ID)
+		notFound = fmt.Errorf("user %w with ID: %s", ErrNotFound, key.ID)
+	case key.Email != "":
+		user, err = s.users.GetByEmail(ctx, t, key.Email)
+		notFound = fmt.Errorf("user %w with email: %s", ErrNotFound, key.Email)
+	case key.Username != "":
+		user, err = s.users.GetByUsername(ctx, t, key.Username)
+		notFound = fmt.Errorf("user %w with username: %s", ErrNotFound, key.Username)
+	default:
+		return nil, fmt.Errorf("%w: missing ID, email, or username", ErrInvalidArgument)
+	}
+
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, notFound
+	}
+	if err == nil && (user.GetTenant() != t || (user.GetDeletedAt() != nil && !showDeleted)) {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, translate(err)
+	}
+	return withETag(user), nil
+}
+
+// BatchGetUsers returns the users identified by keys, in the same order.
+// Users that don't exist or are soft-deleted are nil.
+func (s *UserService) BatchGetUsers(ctx context.Context, keys []UserKey) ([]*pb.User, error) {
+	if len(keys) > MaxBatchGetUsers {
+		return nil, fmt.Errorf("%w: at most %d users can be fetched at once", ErrInvalidArgument, MaxBatchGetUsers)
+	}
+
+	users := make([]*pb.User, len(keys))
+	for i, key := range keys {
+		user, err := s.GetUser(ctx, key, false)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			// Left nil
+		case err != nil:
+			return nil, fmt.Errorf("identifier %d: %w", i, err)
+		default:
+			users[i] = user
+		}
+	}
+	return users, nil
+}
+
+// UpdateUser applies update to the user and stamps its update time.
+// Renamed usernames and changed emails must not be taken by another user.
+func (s *UserService) UpdateUser(ctx context.Context, update UserUpdate) (*pb.User, error) {
+	for _, path := range update.Paths {
+		switch path {
+		case FieldUsername:
+			if update.Username == "" {
+				return nil, fmt.Errorf("%w: missing username", ErrInvalidArgument)
+			}
+		case FieldEmail:
+			if update.Email == "" {
+				return nil, fmt.Errorf("%w: missing email", ErrInvalidArgument)
+			}
+		default:
+			return nil, fmt.Errorf("%w: field %s cannot be updated", ErrInvalidArgument, path)
+		}
+	}
+
+	user, err := s.GetUser(ctx, update.Key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var revision uint64
+	user, err = s.users.Update(ctx, user.GetId(), func(user *pb.User) error {
+		if user.GetDeletedAt() != nil {
+			// Deleted since it was looked up
+			return repositories.ErrUserNotFound
+		}
+		if err := checkETag(user, update.ETag); err != nil {
+			return err
+		}
+		for _, path := range update.Paths {
+			switch path {
+			case FieldUsername:
+				user.Username = update.Username
+			case FieldEmail:
+				user.Email = update.Email
+			}
+		}
+
+		user.UpdatedAt = s.nextUpdatedAt(user)
+		revision = s.events.reserve()
+		return nil
+	})
+	if err != nil {
+		s.events.cancel(revision)
+	}
+	var conflict *repositories.ConflictError
+	if errors.As(err, &conflict) {
+		switch conflict.Field {
+		case FieldUsername:
+			return nil, s.conflictWith(ctx, FieldUsername, update.Username, s.users.GetByUsername)
+		case FieldEmail:
+			return nil, s.conflictWith(ctx, FieldEmail, update.Email, s.users.GetByEmail)
+		}
+	}
+	if errors.Is(err, ErrAborted) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, translate(err)
+	}
+	withETag(user)
+	s.events.publish(revision, EventUpdated, user)
+	return user, nil
+}
+
+// DeleteUser soft-deletes the user identified by key and returns it. The
+// user keeps its username and email until it is purged. When etag is set,
+// the deletion fails with ErrAborted if the user's etag changed.
+func (s *UserService) DeleteUser(ctx context.Context, key UserKey, etag string) (*pb.User, error) {
+	user, err := s.GetUser(ctx, key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var revision uint64
+	user, err = s.users.Update(ctx, user.GetId(), func(user *pb.User) error {
+		if user.GetDeletedAt() != nil {
+			// Deleted since it was looked up
+			return repositories.ErrUserNotFound
+		}
+		if err := checkETag(user, etag); err != nil {
+			return err
+		}
+		user.UpdatedAt = s.nextUpdatedAt(user)
+		user.DeletedAt = timestamppb.New(user.GetUpdatedAt().AsTime())
+		revision = s.events.reserve()
+		return nil
+	})
+	if err != nil {
+		s.events.cancel(revision)
+	}
+	if errors.Is(err, ErrAborted) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, translate(err)
+	}
+	withETag(user)
+	s.events.publish(revision, EventDeleted, user)
+	return user, nil
+}
+
+// UndeleteUser restores the soft-deleted user identified by key. It fails
+// with ErrFailedPrecondition if the user is not deleted.
+func (s *UserService) UndeleteUser(ctx context.Context, key UserKey) (*pb.User, error) {
+	user, err := s.GetUser(ctx, key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	errNotDeleted := fmt.Errorf("%w: user %s is not deleted", ErrFailedPrecondition, user.GetId())
+	if user.GetDeletedAt() == nil {
+		return nil, errNotDeleted
+	}
+	var revision uint64
+	user, err = s.users.Update(ctx, user.GetId(), func(user *pb.User) error {
+		if user.GetDeletedAt() == nil {
+			return errNotDeleted
+		}
+		user.DeletedAt = nil
+		user.UpdatedAt = s.nextUpdatedAt(user)
+		revision = s.events.reserve()
+		return nil
+	})
+	if err != nil {
+		s.events.cancel(revision)
+	}
+	if errors.Is(err, ErrFailedPrecondition) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, translate(err)
+	}
+	withETag(user)
+	// Watchers forgot the user when it was deleted, it reappears to them as created
+	s.events.publish(revision, EventCreated, user)
+	return user, nil
+}
+
+// PurgeDeletedUsers permanently removes the users soft-deleted for longer
+// than retention and returns how many were removed. Watchers aren't notified,
+// they already got the deletions.
+func (s *UserService) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int, error) {
+	purged, err := s.users.Purge(ctx, s.now().Add(-retention))
+	if err != nil {
+		return 0, translate(err)
+	}
+	return purged, nil
+}
+
+// ListUsers returns the page of users selected by query
+func (s *UserService) ListUsers(ctx context.Context, query ListUsersQuery) (*UserPage, error) {
+	opts, err := listOptions(query)
+	if err != nil {
+		return nil, err
+	}
+	opts.Tenant = tenantOf(ctx)
+
+	fingerprint := queryFingerprint(opts)
+	if query.PageToken != "" {
+		cursor, err := s.pageTokens.decode(query.PageToken)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Query != fingerprint {
+			return nil, fmt.Errorf("%w: page token does not match the request", ErrInvalidArgument)
+		}
+		opts.After = &repositories.ListCursor{Username: cursor.Username, CreatedAt: cursor.CreatedAt, ID: cursor.ID}
+	}
+
+	// One more user than the page tells if there is a next page
+	pageSize := opts.Limit
+	opts.Limit++
+	users, total, err := s.users.List(ctx, opts)
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	page := &UserPage{TotalSize: total}
+	if len(users) > pageSize {
+		users = users[:pageSize]
+		last := repositories.CursorOf(users[pageSize-1])
+		page.NextPageToken = s.pageTokens.encode(pageCursor{
+			Username:  last.Username,
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+			Query:     fingerprint,
+		})
+	}
+	for _, user := range users {
+		withETag(user)
+	}
+	page.Users = users
+	return page, nil
+}
+
+// WatchUsers subscribes to the changes made through the service to the users
+// of the tenant of ctx after afterRevision, or to the new ones when it is
+// zero. Resuming from a revision that is no longer retained, or from a
+// previous run of the server, fails with ErrOutOfRange.
+func (s *UserService) WatchUsers(ctx context.Context, afterRevision uint64) (*Subscription, error) {
+	return s.events.subscribe(tenantOf(ctx), afterRevision)
+}
+
+// listOptions validates query and converts it to repository list options, without the cursor
+func listOptions(query ListUsersQuery) (repositories.ListOptions, error) {
+	opts := repositories.ListOptions{
+		OrderBy:        repositories.OrderByUsername,
+		UsernamePrefix: query.UsernamePrefix,
+		EmailPrefix:    query.EmailPrefix,
+		ShowDeleted:    query.ShowDeleted,
+		Limit:          query.PageSize,
+	}
+
+	switch {
+	case query.PageSize < 0:
+		return opts, fmt.Errorf("%w: negative page size", ErrInvalidArgument)
+	case query.PageSize == 0:
+		opts.Limit = DefaultPageSize
+	case query.PageSize > MaxPageSize:
+		opts.Limit = MaxPageSize
+	}
+
+	if query.OrderBy != "" {
+		fields := strings.Fields(query.OrderBy)
+		if len(fields) == 2 && fields[1] == "desc" {
+			opts.Descending = true
+		} else if len(fields) != 1 {
+			return opts, fmt.Errorf("%w: invalid order by: %s", ErrInvalidArgument, query.OrderBy)
+		}
+		switch fields[0] {
+		case repositories.OrderByUsername, repositories.OrderByCreatedAt:
+			opts.OrderBy = fields[0]
+		default:
+			return opts, fmt.Errorf("%w: invalid order by: %s", ErrInvalidArgument, query.OrderBy)
+		}
+	}
+	return opts, nil
+}
+
+// queryFingerprint identifies the users selected by opts, regardless of pagination
+func queryFingerprint(opts repositories.ListOptions) string {
+	h := sha256.Sum256([]byte(fmt.Sprintf("%q %q %t %q %q %t", opts.Tenant, opts.OrderBy, opts.Descending, opts.UsernamePrefix, opts.EmailPrefix, opts.ShowDeleted)))
+	return base64.RawURLEncoding.EncodeToString(h[:8])
+}
+
+// nextUpdatedAt returns the current time, or a time just after the user's
+// last update if the clock is behind it, so UpdatedAt never goes backwards
+func (s *UserService) nextUpdatedAt(user *pb.User) *timestamppb.Timestamp {
+	now := s.now()
+	if last := user.GetUpdatedAt(); last != nil && !now.After(last.AsTime()) {
+		// Step by a microsecond, the finest precision every repository stores
+		now = last.AsTime().Add(time.Microsecond)
+	}
+	return timestamppb.New(now)
+}
+
+// now returns the current time, truncated to the microsecond precision every
+// repository stores so users and their etags are the same once read back
+func (s *UserService) now() time.Time {
+	return s.clock.Now().Truncate(time.Microsecond)
+}
+
+// ensureAvailable returns a *ConflictError if a user of the tenant of ctx already has value in field
+func (s *UserService) ensureAvailable(ctx context.Context, field, value string, get func(context.Context, string, string) (*pb.User, error)) error {
+	user, err := get(ctx, tenantOf(ctx), value)
+	if err == nil {
+		return &ConflictError{Field: field, Value: value, Deleted: user.GetDeletedAt() != nil}
+	}
+	if !errors.Is(err, repositories.ErrUserNotFound) {
+		return translate(err)
+	}
+	return nil
+}
+
+// conflictWith returns the *ConflictError of a write that failed because
+// value in field is taken, looking up whether the user holding it is deleted
+func (s *UserService) conflictWith(ctx context.Context, field, value string, get func(context.Context, string, string) (*pb.User, error)) error {
+	var conflict *ConflictError
+	if errors.As(s.ensureAvailable(ctx, field, value, get), &conflict) {
+		return conflict
+	}
+	// The user holding it was purged since, or the lookup failed
+	return &ConflictError{Field: field, Value: value}
+}
+
+// tenantOf returns the tenant ctx acts in, tenant.Default when it has none
+func tenantOf(ctx context.Context) string {
+	if t, ok := tenant.FromContext(ctx); ok && t != "" {
+		return t
+	}
+	return tenant.Default
+}
+
+// translate maps repository errors to domain errors
+func translate(err error) error {
+	var conflict *repositories.ConflictError
+	switch {
+	case errors.As(err, &conflict):
+		return &ConflictError{Field: conflict.Field}
+	case errors.Is(err, repositories.ErrUserNotFound):
+		return fmt.Errorf("user %w", ErrNotFound)
+	case errors.Is(err, repositories.ErrUserAlreadyExists):
+		return fmt.Errorf("user %w", ErrAlreadyExists)
+	default:
+		return err
+	}
+}
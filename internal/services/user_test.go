@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/internal/repositories"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock only moved by the test
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func newTestService(t *testing.T, clock Clock) *UserService {
+	t.Helper()
+	s, err := NewUserService(repositories.NewMemoryUserRepository(), clock, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestTimestamps(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 123456789, time.UTC)
+	clock := &fakeClock{now: start}
+	s := newTestService(t, clock)
+
+	user, err := s.CreateUser(ctx, "alice", "alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Truncated to the microsecond every repository stores
+	want := time.Date(2024, 1, 1, 12, 0, 0, 123456000, time.UTC)
+	if got := user.GetCreatedAt().AsTime(); !got.Equal(want) {
+		t.Errorf("got created_at %v, want %v", got, want)
+	}
+	if got := user.GetUpdatedAt().AsTime(); !got.Equal(want) {
+		t.Errorf("got updated_at %v, want %v", got, want)
+	}
+
+	update := func(email string) time.Time {
+		t.Helper()
+		updated, err := s.UpdateUser(ctx, UserUpdate{Key: UserKey{ID: user.GetId()}, Email: email, Paths: []string{FieldEmail}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !updated.GetCreatedAt().AsTime().Equal(want) {
+			t.Errorf("update changed created_at to %v", updated.GetCreatedAt().AsTime())
+		}
+		return updated.GetUpdatedAt().AsTime()
+	}
+
+	clock.Set(start.Add(time.Second))
+	if got, want := update("alice@example.org"), want.Add(time.Second); !got.Equal(want) {
+		t.Errorf("got updated_at %v, want %v", got, want)
+	}
+
+	// The clock doesn't move, or goes back: updated_at still increases
+	last := want.Add(time.Second)
+	for _, now := range []time.Time{start.Add(time.Second), start.Add(time.Second + 500*time.Nanosecond), start} {
+		clock.Set(now)
+		got := update("alice@example.net")
+		if !got.After(last) {
+			t.Errorf("got updated_at %v at %v, want it after %v", got, now, last)
+		}
+		if got.Sub(last) != time.Microsecond {
+			t.Errorf("got updated_at %v, want a microsecond after %v", got, last)
+		}
+		last = got
+	}
+}
+
+// TestListUsersPagesAcrossChanges checks that users added or deleted between
+// pages don't make the following pages skip or repeat users
+func TestListUsersPagesAcrossChanges(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, SystemClock)
+	for _, name := range []string{"b", "d", "f", "h"} {
+		if _, err := s.CreateUser(ctx, name+"-user", name+"@example.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page, err := s.ListUsers(ctx, ListUsersQuery{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := usernames(page)
+
+	// Before the cursor, which the next pages must not repeat, and after it
+	if _, err := s.CreateUser(ctx, "a-user", "a@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DeleteUser(ctx, UserKey{Username: "b-user"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateUser(ctx, "e-user", "e@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	for page.NextPageToken != "" {
+		if page, err = s.ListUsers(ctx, ListUsersQuery{PageSize: 2, PageToken: page.NextPageToken}); err != nil {
+			t.Fatal(err)
+		}
+		listed = append(listed, usernames(page)...)
+	}
+	if want := []string{"b-user", "d-user", "e-user", "f-user", "h-user"}; fmt.Sprint(listed) != fmt.Sprint(want) {
+		t.Errorf("listed %v, want %v", listed, want)
+	}
+}
+
+func usernames(page *UserPage) []string {
+	var names []string
+	for _, user := range page.Users {
+		names = append(names, user.GetUsername())
+	}
+	return names
+}
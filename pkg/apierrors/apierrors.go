@@ -0,0 +1,117 @@
+// Package apierrors defines the structured error details shared by the gRPC
+// server and its clients, so clients can branch on them instead of parsing messages.
+package apierrors
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain is the google.rpc.ErrorInfo domain of the errors returned by the server
+const Domain = "grpc-go-example.msharbaji.github.com"
+
+// ErrorInfo reasons returned by the server
+const (
+	ReasonUserAlreadyExists = "USER_ALREADY_EXISTS"
+	ReasonMissingPermission = "MISSING_PERMISSION"
+)
+
+// google.rpc.ErrorInfo metadata keys
+const (
+	// FieldMetadataKey names the offending field
+	FieldMetadataKey = "field"
+	// DeletedMetadataKey is "true" when the conflicting user is soft-deleted
+	DeletedMetadataKey = "deleted"
+	// PermissionMetadataKey names the permission the caller is missing
+	PermissionMetadataKey = "permission"
+)
+
+// NewAlreadyExists returns a codes.AlreadyExists status error whose details
+// name the conflicting field in both google.rpc.ErrorInfo and google.rpc.BadRequest
+func NewAlreadyExists(msg, field string) error {
+	return newAlreadyExists(msg, map[string]string{FieldMetadataKey: field})
+}
+
+// NewAlreadyExistsDeleted is NewAlreadyExists for a field taken by a
+// soft-deleted user, its google.rpc.ErrorInfo also has deleted=true
+func NewAlreadyExistsDeleted(msg, field string) error {
+	return newAlreadyExists(msg, map[string]string{FieldMetadataKey: field, DeletedMetadataKey: "true"})
+}
+
+func newAlreadyExists(msg string, metadata map[string]string) error {
+	field := metadata[FieldMetadataKey]
+	st := status.New(codes.AlreadyExists, msg)
+	detailed, err := st.WithDetails(
+		&errdetails.ErrorInfo{
+			Reason:   ReasonUserAlreadyExists,
+			Domain:   Domain,
+			Metadata: metadata,
+		},
+		&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: field, Description: msg},
+			},
+		},
+	)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// ConflictingField returns the field named by an AlreadyExists error returned by the server
+func ConflictingField(err error) (string, bool) {
+	info, ok := Info(err)
+	if !ok || info.GetReason() != ReasonUserAlreadyExists {
+		return "", false
+	}
+	field, ok := info.GetMetadata()[FieldMetadataKey]
+	return field, ok
+}
+
+// ConflictsWithDeleted reports whether an AlreadyExists error returned by the
+// server is caused by a soft-deleted user, which can be undeleted or waited to be purged
+func ConflictsWithDeleted(err error) bool {
+	info, ok := Info(err)
+	return ok && info.GetReason() == ReasonUserAlreadyExists && info.GetMetadata()[DeletedMetadataKey] == "true"
+}
+
+// NewPermissionDenied returns a codes.PermissionDenied status error whose
+// google.rpc.ErrorInfo names the missing permission
+func NewPermissionDenied(msg, permission string) error {
+	st := status.New(codes.PermissionDenied, msg)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   ReasonMissingPermission,
+		Domain:   Domain,
+		Metadata: map[string]string{PermissionMetadataKey: permission},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// MissingPermission returns the permission named by a PermissionDenied error returned by the server
+func MissingPermission(err error) (string, bool) {
+	info, ok := Info(err)
+	if !ok || info.GetReason() != ReasonMissingPermission {
+		return "", false
+	}
+	permission, ok := info.GetMetadata()[PermissionMetadataKey]
+	return permission, ok
+}
+
+// Info returns the google.rpc.ErrorInfo detail of err, if it has one
+func Info(err error) (*errdetails.ErrorInfo, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetDomain() == Domain {
+			return info, true
+		}
+	}
+	return nil, false
+}
@@ -1,38 +1,285 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/internal/repositories"
 	"github.com/msharbaji/grpc-go-example/internal/server"
+	"github.com/msharbaji/grpc-go-example/internal/services"
+	"github.com/msharbaji/grpc-go-example/pkg/middleware"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"github.com/msharbaji/grpc-go-example/pkg/secrets"
+	"github.com/msharbaji/grpc-go-example/pkg/tenant"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
+	"sync"
+	"time"
 )
 
+const (
+	UserStoreMemory   = "memory"
+	UserStoreSQLite   = "sqlite"
+	UserStorePostgres = "postgres"
+)
+
+// Config holds the application settings
+type Config struct {
+	GrpcPort    string
+	HmacSecrets map[string]string
+	// HmacSecretsFile is a JSON or YAML file of HMAC secrets with their
+	// validity periods, used instead of HmacSecrets when set. See secrets.FileProvider.
+	HmacSecretsFile string
+	// HmacSecretsReloadInterval is how often HmacSecretsFile is checked for changes
+	HmacSecretsReloadInterval time.Duration
+	// HmacTenants maps HMAC key IDs to the tenant they act in, tenant.Default
+	// when missing. The keys of HmacSecretsFile have their tenant in it instead.
+	HmacTenants map[string]string
+	// AdminKeys lists the HMAC key IDs that can act in any tenant
+	AdminKeys []string
+	// HmacRoles maps HMAC key IDs to their role, "viewer", "editor" or "owner".
+	// Keys without one are viewers. The keys of HmacSecretsFile have their
+	// role in it instead.
+	HmacRoles map[string]string
+	// RejectLegacySignatures only accepts requests signed with the canonical
+	// scheme, see middleware.HmacVersionCanonical
+	RejectLegacySignatures bool
+	// HmacLegacyCutoff is when legacy signatures, which can be replayed, stop
+	// being accepted even without RejectLegacySignatures, never when zero
+	HmacLegacyCutoff time.Time
+	// HmacClockSkew is how far the timestamp of a signed request may be from
+	// the server clock, middleware.DefaultClockSkew when zero
+	HmacClockSkew time.Duration
+	// HmacRequireSignedMessages rejects the streams whose client doesn't sign
+	// each message, see middleware.SignStreamMessages
+	HmacRequireSignedMessages bool
+	// PublicMethods lists the methods callable without authentication, see server.AuthConfig
+	PublicMethods []string
+	// UserStore selects the user repository backend, one of UserStoreMemory, UserStoreSQLite or UserStorePostgres
+	UserStore string
+	// SQLiteDSN is the SQLite database used when UserStore is UserStoreSQLite
+	SQLiteDSN string
+	// PostgresDSN is the Postgres database used when UserStore is UserStorePostgres
+	PostgresDSN string
+	// PostgresPool limits the connections kept to the Postgres database
+	PostgresPool repositories.PoolConfig
+	// PageTokenSecret signs ListUsers page tokens. Servers sharing a store
+	// must share it, a random one is generated when empty.
+	PageTokenSecret string
+	// PurgeRetention is how long deleted users can be undeleted before being
+	// purged, zero never purges them
+	PurgeRetention time.Duration
+	// PurgeInterval is how often deleted users past their retention are purged
+	PurgeInterval time.Duration
+	// IdempotencyWindow is how long the responses to calls with an idempotency
+	// key are replayed, zero disables idempotency keys
+	IdempotencyWindow time.Duration
+}
+
+// seedUsers are the users the in-memory store starts with
+var seedUsers = []*pb.User{
+	{
+		Id:       "1",
+		Tenant:   tenant.Default,
+		Username: "someone",
+		Email:    "someone@someone.com",
+	},
+	{
+		Id:       "2",
+		Tenant:   tenant.Default,
+		Username: "someone_else",
+		Email:    "someonce2@someone.com",
+		CreatedAt: &timestamppb.Timestamp{
+			Seconds: 1612345678,
+		},
+		UpdatedAt: &timestamppb.Timestamp{
+			Seconds: 1612345678,
+		},
+	},
+}
+
 type App struct {
-	grpcPort    string
-	hmacSecrets map[string]string
+	config      Config
+	users       repositories.UserRepository
+	userService *services.UserService
 	grpcServer  server.Grpc
+	// secretsFile is the provider of the HMAC secrets when they are read from a file
+	secretsFile *secrets.FileProvider
 }
 
-func NewApp(grpcPort string, hmacSecrets map[string]string) (*App, error) {
-	grpcServer, err := server.NewGrpcServer(grpcPort, hmacSecrets)
+func NewApp(config Config) (*App, error) {
+	switch {
+	case config.RejectLegacySignatures:
+	case config.HmacLegacyCutoff.IsZero():
+		log.Warn().Msg("legacy HMAC signatures, which can be replayed, are accepted, set HMAC_REJECT_LEGACY once every client signs with x-hmac-version 2")
+	default:
+		log.Warn().Time("cutoff", config.HmacLegacyCutoff).Msg("legacy HMAC signatures, which can be replayed, are accepted until the cutoff")
+	}
+
+	var (
+		provider    secrets.Provider
+		roles       middleware.RoleProvider
+		tenants     middleware.TenantProvider
+		secretsFile *secrets.FileProvider
+	)
+	if config.HmacSecretsFile != "" {
+		// Keys hot-added to the file must come with their role and tenant
+		if len(config.HmacRoles) > 0 || len(config.HmacTenants) > 0 {
+			return nil, errors.New("HMAC roles and tenants can't be configured with a secrets file, set them in the file")
+		}
+		file, err := secrets.NewFileProvider(config.HmacSecretsFile, secrets.WithRoleCheck(func(name string) error {
+			_, err := middleware.ParseRole(name)
+			return err
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load HMAC secrets: %w", err)
+		}
+		provider, roles, tenants, secretsFile = file, fileRoles{file}, file, file
+	} else {
+		staticRoles, err := parseRoles(config.HmacRoles)
+		if err != nil {
+			return nil, err
+		}
+		for keyID := range config.HmacSecrets {
+			if _, ok := staticRoles[keyID]; !ok {
+				log.Warn().Str("key", keyID).Msg("HMAC key has no role, it can only read, give it one with HMAC_ROLES")
+			}
+		}
+		provider, roles, tenants = secrets.Static(config.HmacSecrets), staticRoles, middleware.Tenants(config.HmacTenants)
+	}
+
+	users, err := newUserRepository(config)
+	if err != nil {
+		return nil, err
+	}
+
+	userService, err := services.NewUserService(users, services.SystemClock, []byte(config.PageTokenSecret))
+	if err != nil {
+		return nil, err
+	}
+
+	grpcServer, err := server.NewGrpcServer(config.GrpcPort, server.AuthConfig{
+		Secrets:   provider,
+		Tenants:   tenants,
+		AdminKeys: config.AdminKeys,
+		Roles:     roles,
+
+		RejectLegacySignatures: config.RejectLegacySignatures,
+		LegacySignaturesCutoff: config.HmacLegacyCutoff,
+		ClockSkew:              config.HmacClockSkew,
+		PublicMethods:          config.PublicMethods,
+
+		RequireSignedStreamMessages: config.HmacRequireSignedMessages,
+	}, userService, config.IdempotencyWindow)
 	if err != nil {
 		return nil, err
 	}
 
 	return &App{
-		grpcPort:    grpcPort,
-		hmacSecrets: hmacSecrets,
+		config:      config,
+		users:       users,
+		userService: userService,
 		grpcServer:  *grpcServer,
+		secretsFile: secretsFile,
 	}, nil
 }
 
 func (a App) Run() error {
 	go a.grpcServer.Start()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		a.purgeDeletedUsers(ctx)
+	}()
+	if a.secretsFile != nil && a.config.HmacSecretsReloadInterval > 0 {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			a.secretsFile.Watch(ctx, a.config.HmacSecretsReloadInterval)
+		}()
+	}
+
 	a.grpcServer.HandleShutdown()
 	// Initiate graceful shutdown
 	log.Info().Msg("Received termination signal. Shutting down gRPC server...")
+	cancel()
 	if err := a.grpcServer.Stop(); err != nil {
 		return err
 	}
+	background.Wait()
+
+	if closer, ok := a.users.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("failed to close user store: %w", err)
+		}
+	}
 
 	return nil
 }
+
+// purgeDeletedUsers periodically purges the users deleted for longer than the
+// retention period, until ctx is done
+func (a App) purgeDeletedUsers(ctx context.Context) {
+	if a.config.PurgeRetention <= 0 || a.config.PurgeInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(a.config.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := a.userService.PurgeDeletedUsers(ctx, a.config.PurgeRetention)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to purge deleted users")
+				continue
+			}
+			if purged > 0 {
+				log.Info().Int("purged", purged).Msg("purged deleted users")
+			}
+		}
+	}
+}
+
+// parseRoles returns the roles named by names, by key ID
+func parseRoles(names map[string]string) (middleware.Roles, error) {
+	roles := make(middleware.Roles, len(names))
+	for keyID, name := range names {
+		role, err := middleware.ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid role of key %s: %w", keyID, err)
+		}
+		roles[keyID] = role
+	}
+	return roles, nil
+}
+
+// fileRoles are the roles of the keys of a secrets file, checked when it is loaded
+type fileRoles struct {
+	file *secrets.FileProvider
+}
+
+func (r fileRoles) Role(keyID string) (middleware.Role, bool) {
+	name, ok := r.file.Role(keyID)
+	return middleware.Role(name), ok
+}
+
+// newUserRepository creates the user repository selected by the config
+func newUserRepository(config Config) (repositories.UserRepository, error) {
+	switch config.UserStore {
+	case UserStoreMemory, "":
+		return repositories.NewMemoryUserRepository(seedUsers...), nil
+	case UserStoreSQLite:
+		return repositories.NewSQLiteUserRepository(context.Background(), config.SQLiteDSN)
+	case UserStorePostgres:
+		return repositories.NewPostgresUserRepository(context.Background(), config.PostgresDSN, config.PostgresPool)
+	default:
+		return nil, fmt.Errorf("unknown user store: %s", config.UserStore)
+	}
+}
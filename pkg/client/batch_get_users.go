@@ -0,0 +1,25 @@
+package client
+
+import (
+	"context"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+)
+
+// ByID identifies a user by ID
+func ByID(id string) *pb.UserIdentifier {
+	return &pb.UserIdentifier{Id: &id}
+}
+
+// ByUsername identifies a user by username
+func ByUsername(username string) *pb.UserIdentifier {
+	return &pb.UserIdentifier{Username: &username}
+}
+
+// ByEmail identifies a user by email
+func ByEmail(email string) *pb.UserIdentifier {
+	return &pb.UserIdentifier{Email: &email}
+}
+
+func (c *client) BatchGetUsers(ctx context.Context, identifiers ...*pb.UserIdentifier) (*pb.BatchGetUsersResponse, error) {
+	return c.UserServiceClient.BatchGetUsers(ctx, &pb.BatchGetUsersRequest{Identifiers: identifiers})
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"io"
+	"strings"
+)
+
+// BulkFormat is the encoding of the users read by BulkCreateUsers
+type BulkFormat int
+
+const (
+	// BulkFormatCSV is a CSV file whose header names the username and email columns
+	BulkFormatCSV BulkFormat = iota
+	// BulkFormatNDJSON is one JSON CreateUserRequest per line, e.g. {"username": "someone", "email": "someone@example.com"}
+	BulkFormatNDJSON
+)
+
+func (c *client) BulkCreateUsers(ctx context.Context, r io.Reader, format BulkFormat, allOrNothing bool) (*pb.BulkCreateUsersResponse, error) {
+	var next func() (*pb.CreateUserRequest, error)
+	switch format {
+	case BulkFormatCSV:
+		records, err := newCSVUsers(r)
+		if err != nil {
+			return nil, err
+		}
+		next = records
+	case BulkFormatNDJSON:
+		next = newNDJSONUsers(r)
+	default:
+		return nil, fmt.Errorf("invalid bulk format: %d", format)
+	}
+
+	stream, err := c.UserServiceClient.BulkCreateUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for first := true; ; first = false {
+		user, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			_ = stream.CloseSend()
+			return nil, err
+		}
+		req := &pb.BulkCreateUsersRequest{User: user, AllOrNothing: first && allOrNothing}
+		if err := stream.Send(req); err != nil {
+			if errors.Is(err, io.EOF) {
+				// The server ended the call, CloseAndRecv returns its status
+				break
+			}
+			return nil, err
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+// newCSVUsers returns a function reading the next user of a CSV file
+func newCSVUsers(r io.Reader) (func() (*pb.CreateUserRequest, error), error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	usernameCol, emailCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "username":
+			usernameCol = i
+		case "email":
+			emailCol = i
+		}
+	}
+	if usernameCol < 0 || emailCol < 0 {
+		return nil, fmt.Errorf("csv header must name the username and email columns: %v", header)
+	}
+
+	return func() (*pb.CreateUserRequest, error) {
+		record, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("failed to read csv record: %w", err)
+		}
+		return &pb.CreateUserRequest{
+			Username: record[usernameCol],
+			Email:    record[emailCol],
+		}, nil
+	}, nil
+}
+
+// newNDJSONUsers returns a function reading the next user of a NDJSON file, skipping blank lines
+func newNDJSONUsers(r io.Reader) func() (*pb.CreateUserRequest, error) {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	return func() (*pb.CreateUserRequest, error) {
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			user := &pb.CreateUserRequest{}
+			if err := protojson.Unmarshal([]byte(text), user); err != nil {
+				return nil, fmt.Errorf("failed to parse line %d: %w", line, err)
+			}
+			return user, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read ndjson: %w", err)
+		}
+		return nil, io.EOF
+	}
+}
@@ -8,7 +8,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/protobuf/types/known/emptypb"
+	"io"
 	"strings"
 )
 
@@ -17,19 +17,60 @@ type Client interface {
 	GetVersion(ctx context.Context, version string) (*pb.GetVersionResponse, error)
 
 	// GetUser GetUser user by id, email or username.
+	//
+	// Deprecated: identifierType is unchecked until the call, use GetUserBy.
 	GetUser(ctx context.Context, identifier string, identifierType string) (*pb.GetUserResponse, error)
 
-	// ListUsers ListUsers list all users.
-	ListUsers(tx context.Context) (*pb.ListUsersResponse, error)
+	// GetUserBy get a user identified with ByID, ByUsername or ByEmail.
+	GetUserBy(ctx context.Context, identifier *pb.UserIdentifier) (*pb.GetUserResponse, error)
+
+	// BatchGetUsers get several users in one call, identified with ByID, ByUsername or ByEmail.
+	// The response lists the found users and the missing identifiers, both in request order.
+	BatchGetUsers(ctx context.Context, identifiers ...*pb.UserIdentifier) (*pb.BatchGetUsersResponse, error)
+
+	// ListUsers ListUsers list a page of users.
+	ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error)
+
+	// ListAllUsers iterate over the users of every page of req.
+	ListAllUsers(ctx context.Context, req *pb.ListUsersRequest) *UserIterator
 
 	// CreateUser create a new user.
+	// A taken username or email fails with codes.AlreadyExists, use apierrors.ConflictingField to tell which
+	// and apierrors.ConflictsWithDeleted whether it is held by a soft-deleted user.
 	CreateUser(ctx context.Context, username string, email string) (*pb.CreateUserResponse, error)
 
 	// UpdateUser update a user.
+	// Use NewUpdateUserRequest to build a request with an update mask.
 	UpdateUser(ctx context.Context, user *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error)
 
-	// DeleteUser delete a user.
+	// ModifyUser read the user with the given ID, pass it to fn and apply the updates it returns,
+	// unless the user changed in between: then it starts over, a few times before giving up with codes.Aborted.
+	// fn may return ErrNoChange to leave the user as it is.
+	ModifyUser(ctx context.Context, id string, fn func(user *pb.User) ([]UpdateUserOption, error)) (*pb.User, error)
+
+	// DeleteUser soft-delete a user, it can be undeleted until purged.
+	//
+	// Deprecated: identifierType is unchecked until the call, use DeleteUserBy.
 	DeleteUser(ctx context.Context, identifier string, identifierType string) (*pb.DeleteUserResponse, error)
+
+	// DeleteUserBy soft-delete a user identified with ByID, ByUsername or ByEmail, it can be undeleted until purged.
+	DeleteUserBy(ctx context.Context, identifier *pb.UserIdentifier) (*pb.DeleteUserResponse, error)
+
+	// UndeleteUser restore a soft-deleted user, identified with ByID, ByUsername or ByEmail.
+	UndeleteUser(ctx context.Context, identifier *pb.UserIdentifier) (*pb.UndeleteUserResponse, error)
+
+	// WatchUsers call fn with every user change after afterRevision, or with the new ones when it is zero,
+	// until ctx is done or fn returns an error. Broken streams are reopened after a backoff,
+	// resuming from the last revision passed to fn, or from where the watch started.
+	WatchUsers(ctx context.Context, afterRevision uint64, fn func(event *pb.UserEvent) error) error
+
+	// BulkCreateUsers create the users read from r in a single streaming call and return the outcome of each one.
+	// With allOrNothing the users are only created if they all can be.
+	BulkCreateUsers(ctx context.Context, r io.Reader, format BulkFormat, allOrNothing bool) (*pb.BulkCreateUsersResponse, error)
+
+	// SyncUsers reconcile the users with the desired users read from r and call fn with every action taken.
+	// See SyncOptions for dry runs and deleting the users missing from r.
+	SyncUsers(ctx context.Context, r io.Reader, format BulkFormat, opts SyncOptions, fn func(res *pb.SyncUsersResponse) error) error
 }
 
 type client struct {
@@ -37,10 +78,8 @@ type client struct {
 	pb.UserServiceClient
 }
 
-func (c *client) ListUsers(ctx context.Context) (*pb.ListUsersResponse, error) {
-	empty := &emptypb.Empty{}
-
-	res, err := c.UserServiceClient.ListUsers(ctx, empty)
+func (c *client) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	res, err := c.UserServiceClient.ListUsers(ctx, req)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to list users")
 		return nil, err
@@ -63,20 +102,19 @@ func (c *client) GetVersion(ctx context.Context, version string) (*pb.GetVersion
 }
 
 func (c *client) GetUser(ctx context.Context, identifier string, identifierType string) (*pb.GetUserResponse, error) {
-	userReq := &pb.GetUserRequest{}
-
-	switch strings.ToLower(identifierType) {
-	case "id":
-		userReq.Id = &identifier
-	case "email":
-		userReq.Email = &identifier
-	case "username":
-		userReq.Username = &identifier
-	default:
-		return nil, fmt.Errorf("invalid identifier type: %s", identifierType)
+	userIdentifier, err := identifierOf(identifier, identifierType)
+	if err != nil {
+		return nil, err
 	}
+	return c.GetUserBy(ctx, userIdentifier)
+}
 
-	res, err := c.UserServiceClient.GetUser(ctx, userReq)
+func (c *client) GetUserBy(ctx context.Context, identifier *pb.UserIdentifier) (*pb.GetUserResponse, error) {
+	res, err := c.UserServiceClient.GetUser(ctx, &pb.GetUserRequest{
+		Id:       identifier.Id,
+		Username: identifier.Username,
+		Email:    identifier.Email,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -106,31 +144,60 @@ func (c *client) UpdateUser(ctx context.Context, user *pb.UpdateUserRequest) (*p
 }
 
 func (c *client) DeleteUser(ctx context.Context, identifier string, identifierType string) (*pb.DeleteUserResponse, error) {
+	userIdentifier, err := identifierOf(identifier, identifierType)
+	if err != nil {
+		return nil, err
+	}
+	return c.DeleteUserBy(ctx, userIdentifier)
+}
 
-	userReq := &pb.DeleteUserRequest{}
+func (c *client) DeleteUserBy(ctx context.Context, identifier *pb.UserIdentifier) (*pb.DeleteUserResponse, error) {
+	res, err := c.UserServiceClient.DeleteUser(ctx, &pb.DeleteUserRequest{
+		Id:       identifier.Id,
+		Username: identifier.Username,
+		Email:    identifier.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
 
+// identifierOf converts the identifier of the deprecated string typed methods
+func identifierOf(identifier string, identifierType string) (*pb.UserIdentifier, error) {
 	switch strings.ToLower(identifierType) {
 	case "id":
-		userReq.Id = &identifier
+		return ByID(identifier), nil
 	case "email":
-		userReq.Email = &identifier
+		return ByEmail(identifier), nil
 	case "username":
-		userReq.Username = &identifier
+		return ByUsername(identifier), nil
 	default:
 		return nil, fmt.Errorf("invalid identifier type: %s", identifierType)
 	}
-	res, err := c.UserServiceClient.DeleteUser(ctx, userReq)
+}
 
-	if err != nil {
-		return nil, err
-	}
-	return res, nil
+func (c *client) UndeleteUser(ctx context.Context, identifier *pb.UserIdentifier) (*pb.UndeleteUserResponse, error) {
+	return c.UserServiceClient.UndeleteUser(ctx, &pb.UndeleteUserRequest{
+		Id:       identifier.Id,
+		Username: identifier.Username,
+		Email:    identifier.Email,
+	})
 }
 
-// NewClient creates a new grpc client
-func NewClient(endpoint, hmacKeyID, hmacSecret string) (Client, error) {
+// NewClient creates a new grpc client. Requests are checked against the
+// validation rules of their messages before being sent, see pkg/validate.
+// Streams are signed when opened, pass middleware.SignStreamMessages to also
+// sign each message sent on them.
+func NewClient(endpoint, hmacKeyID, hmacSecret string, authOpts ...middleware.ClientAuthOption) (Client, error) {
 	opts := []grpc.DialOption{
-		grpc.WithUnaryInterceptor(middleware.NewClientAuthInterceptor(hmacKeyID, hmacSecret)),
+		grpc.WithChainUnaryInterceptor(
+			middleware.NewClientValidationInterceptor(),
+			middleware.NewClientAuthInterceptor(hmacKeyID, hmacSecret),
+		),
+		grpc.WithChainStreamInterceptor(
+			middleware.NewClientStreamAuthInterceptor(hmacKeyID, hmacSecret, authOpts...),
+		),
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 	}
 	conn, err := grpc.Dial(endpoint, opts...)
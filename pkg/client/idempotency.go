@@ -0,0 +1,15 @@
+package client
+
+import (
+	"context"
+	"github.com/msharbaji/grpc-go-example/pkg/middleware"
+	"google.golang.org/grpc/metadata"
+)
+
+// WithIdempotencyKey returns a context whose CreateUser, UpdateUser and
+// DeleteUser calls carry key, so retrying them with the same key returns the
+// response of the first call instead of applying them again. Use a new key,
+// e.g. a random UUID, for every distinct operation.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, middleware.IdempotencyKeyHeader, key)
+}
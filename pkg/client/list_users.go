@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// UserIterator walks the users of every page of a ListUsers request,
+// fetching the pages as it goes.
+//
+//	it := c.ListAllUsers(ctx, &pb.ListUsersRequest{OrderBy: "created_at"})
+//	for it.Next() {
+//		user := it.User()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type UserIterator struct {
+	ctx   context.Context //nolint:containedctx
+	c     *client
+	req   *pb.ListUsersRequest
+	users []*pb.User
+	user  *pb.User
+	done  bool
+	err   error
+}
+
+func (c *client) ListAllUsers(ctx context.Context, req *pb.ListUsersRequest) *UserIterator {
+	req, _ = proto.Clone(req).(*pb.ListUsersRequest)
+	if req == nil {
+		req = &pb.ListUsersRequest{}
+	}
+	return &UserIterator{
+		ctx: ctx,
+		c:   c,
+		req: req,
+	}
+}
+
+// Next advances to the next user, fetching the next page when needed. It
+// returns false when all users have been visited or a request failed.
+func (it *UserIterator) Next() bool {
+	for len(it.users) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		res, err := it.c.ListUsers(it.ctx, it.req)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.users = res.GetUsers()
+		it.req.PageToken = res.GetNextPageToken()
+		it.done = it.req.PageToken == ""
+	}
+
+	it.user, it.users = it.users[0], it.users[1:]
+	return true
+}
+
+// User returns the current user
+func (it *UserIterator) User() *pb.User {
+	return it.user
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *UserIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"io"
+)
+
+// SyncOptions configures SyncUsers
+type SyncOptions struct {
+	// DryRun reports the actions without applying them
+	DryRun bool
+	// DeleteMissing deletes the users missing from the desired users
+	DeleteMissing bool
+}
+
+func (c *client) SyncUsers(ctx context.Context, r io.Reader, format BulkFormat, opts SyncOptions, fn func(res *pb.SyncUsersResponse) error) error {
+	var next func() (*pb.CreateUserRequest, error)
+	switch format {
+	case BulkFormatCSV:
+		records, err := newCSVUsers(r)
+		if err != nil {
+			return err
+		}
+		next = records
+	case BulkFormatNDJSON:
+		next = newNDJSONUsers(r)
+	default:
+		return fmt.Errorf("invalid bulk format: %d", format)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.UserServiceClient.SyncUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Send while receiving, so the server is never blocked on a full stream
+	sendErr := make(chan error, 1)
+	go func() {
+		err := sendSyncUsers(stream, next, opts)
+		sendErr <- err
+		if err != nil {
+			// Abort the call rather than closing it, the server would otherwise
+			// delete the users that couldn't be read in delete-missing mode
+			cancel()
+		}
+	}()
+
+	for {
+		res, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			select {
+			case err := <-sendErr:
+				if err != nil {
+					return err
+				}
+			default:
+			}
+			return err
+		}
+		if err := fn(res); err != nil {
+			return err
+		}
+	}
+	return <-sendErr
+}
+
+// sendSyncUsers streams the users returned by next, then closes the sending
+// side of stream. It leaves it open when next fails.
+func sendSyncUsers(stream pb.UserService_SyncUsersClient, next func() (*pb.CreateUserRequest, error), opts SyncOptions) error {
+	for first := true; ; first = false {
+		user, err := next()
+		if errors.Is(err, io.EOF) {
+			return stream.CloseSend()
+		}
+		if err != nil {
+			return err
+		}
+		req := &pb.SyncUsersRequest{User: user}
+		if first {
+			req.DryRun = opts.DryRun
+			req.DeleteMissing = opts.DeleteMissing
+		}
+		if err := stream.Send(req); err != nil {
+			if errors.Is(err, io.EOF) {
+				// The server ended the call, Recv returns its status
+				return nil
+			}
+			return err
+		}
+	}
+}
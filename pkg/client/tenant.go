@@ -0,0 +1,13 @@
+package client
+
+import (
+	"context"
+	"github.com/msharbaji/grpc-go-example/pkg/middleware"
+	"google.golang.org/grpc/metadata"
+)
+
+// WithTenant returns a context whose calls act in tenant rather than in the
+// tenant of the client's HMAC key. Only admin keys can use another tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, middleware.TenantHeader, tenant)
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// maxModifyUserAttempts caps the number of times ModifyUser reads and updates a user that keeps changing
+const maxModifyUserAttempts = 5
+
+// ErrNoChange can be returned by a ModifyUser function to leave the user as it is
+var ErrNoChange = errors.New("no change")
+
+// UpdateUserOption sets a field to update on an UpdateUserRequest and adds it to the update mask.
+type UpdateUserOption func(req *pb.UpdateUserRequest)
+
+// UpdateUsername renames the user to username
+func UpdateUsername(username string) UpdateUserOption {
+	return func(req *pb.UpdateUserRequest) {
+		req.Username = &username
+		req.UpdateMask.Paths = append(req.UpdateMask.Paths, "username")
+	}
+}
+
+// UpdateEmail changes the email of the user to email
+func UpdateEmail(email string) UpdateUserOption {
+	return func(req *pb.UpdateUserRequest) {
+		req.Email = &email
+		req.UpdateMask.Paths = append(req.UpdateMask.Paths, "email")
+	}
+}
+
+// IfMatch only applies the update if the user still has etag, so changes
+// made since it was read aren't overwritten. It is not part of the update mask.
+func IfMatch(etag string) UpdateUserOption {
+	return func(req *pb.UpdateUserRequest) {
+		req.Etag = etag
+	}
+}
+
+// NewUpdateUserRequest builds a request updating the user with the given ID,
+// with an update mask listing exactly the fields set by opts.
+//
+//	req := client.NewUpdateUserRequest(id, client.UpdateUsername("new-name"), client.UpdateEmail("new@example.com"))
+//	res, err := c.UpdateUser(ctx, req)
+func NewUpdateUserRequest(id string, opts ...UpdateUserOption) *pb.UpdateUserRequest {
+	req := &pb.UpdateUserRequest{
+		Id:         id,
+		UpdateMask: &fieldmaskpb.FieldMask{},
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req
+}
+
+func (c *client) ModifyUser(ctx context.Context, id string, fn func(user *pb.User) ([]UpdateUserOption, error)) (*pb.User, error) {
+	for attempt := 1; ; attempt++ {
+		res, err := c.UserServiceClient.GetUser(ctx, &pb.GetUserRequest{Id: &id})
+		if err != nil {
+			return nil, err
+		}
+		user := res.GetUser()
+
+		opts, err := fn(user)
+		if errors.Is(err, ErrNoChange) {
+			return user, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		updated, err := c.UpdateUser(ctx, NewUpdateUserRequest(id, append(opts, IfMatch(user.GetEtag()))...))
+		if status.Code(err) == codes.Aborted && attempt < maxModifyUserAttempts {
+			// Changed by someone else since it was read, start over from the new version
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return updated.GetUser(), nil
+	}
+}
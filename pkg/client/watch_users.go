@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	watchMinBackoff = 100 * time.Millisecond
+	watchMaxBackoff = 5 * time.Second
+	// watchRevisionHeader is the header metadata of WatchUsers streams holding
+	// the revision the watch starts after
+	watchRevisionHeader = "x-watch-revision"
+)
+
+func (c *client) WatchUsers(ctx context.Context, afterRevision uint64, fn func(event *pb.UserEvent) error) error {
+	backoff := watchMinBackoff
+	for {
+		stream, err := c.UserServiceClient.WatchUsers(ctx, &pb.WatchUsersRequest{AfterRevision: afterRevision})
+		if err == nil && afterRevision == 0 {
+			// Resume from where the watch started if it breaks before the first event
+			afterRevision = startRevision(stream)
+		}
+		for err == nil {
+			var event *pb.UserEvent
+			event, err = stream.Recv()
+			if err != nil {
+				break
+			}
+			if err := fn(event); err != nil {
+				return err
+			}
+			afterRevision = event.GetRevision()
+			backoff = watchMinBackoff
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !resumable(err) {
+			log.Error().Err(err).Msg("failed to watch users")
+			return err
+		}
+
+		log.Debug().Err(err).Uint64("revision", afterRevision).Dur("backoff", backoff).Msg("resuming users watch")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > watchMaxBackoff {
+			backoff = watchMaxBackoff
+		}
+	}
+}
+
+// startRevision returns the revision the watch of stream starts after, or
+// zero when the server didn't tell, Recv then reports why if the stream failed
+func startRevision(stream pb.UserService_WatchUsersClient) uint64 {
+	header, err := stream.Header()
+	if err != nil {
+		return 0
+	}
+	values := header.Get(watchRevisionHeader)
+	if len(values) != 1 {
+		return 0
+	}
+	revision, _ := strconv.ParseUint(values[0], 10, 64)
+	return revision
+}
+
+// resumable reports whether a watch that failed with err can be resumed.
+// Other errors, such as OUT_OF_RANGE once the revision is no longer retained
+// or comes from a previous run of the server, would fail again.
+func resumable(err error) bool {
+	if errors.Is(err, io.EOF) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
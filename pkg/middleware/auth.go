@@ -4,34 +4,149 @@ import (
 	"bytes"
 	"context"
 	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/base64"
 	"encoding/gob"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"github.com/msharbaji/grpc-go-example/pkg/secrets"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// HmacVersionHeader is the metadata key of the version of the signing scheme of a request
+	HmacVersionHeader = "x-hmac-version"
+	// HmacTimestampHeader is the metadata key of the time a request was signed at, in Unix seconds
+	HmacTimestampHeader = "x-hmac-timestamp"
+	// HmacNonceHeader is the metadata key of the random value making each signed request unique
+	HmacNonceHeader = "x-hmac-nonce"
+
+	// DefaultClockSkew is how far the timestamp of a request may be from the server clock
+	DefaultClockSkew = 5 * time.Minute
+
+	minNonceLength = 16
+	maxNonceLength = 64
+	// maxNonces bounds the memory used by the nonces seen within the clock skew
+	// window, requests are refused rather than risk accepting a replay when full
+	maxNonces = 1000000
+)
+
+// Signing scheme versions
+const (
+	// HmacVersionLegacy signs the gob encoding of the Go request struct, only
+	// Go clients can compute it. It is assumed when x-hmac-version is missing.
+	HmacVersionLegacy = "1"
+	// HmacVersionCanonical signs the canonical string of the request, see canonicalString
+	HmacVersionCanonical = "2"
 )
 
 var (
-	ErrMissingMetadata  = status.Errorf(codes.InvalidArgument, "missing metadata")
-	ErrMissingHmac      = status.Errorf(codes.InvalidArgument, "missing x-hmac-signature metadata")
-	ErrMissingHmacKeyID = status.Errorf(codes.InvalidArgument, "missing x-hmac-key-id metadata")
-	ErrUnauthorized     = status.Errorf(codes.Unauthenticated, "unauthorized")
+	ErrMissingMetadata     = status.Errorf(codes.InvalidArgument, "missing metadata")
+	ErrMissingHmac         = status.Errorf(codes.InvalidArgument, "missing x-hmac-signature metadata")
+	ErrMissingHmacKeyID    = status.Errorf(codes.InvalidArgument, "missing x-hmac-key-id metadata")
+	ErrUnsupportedHmac     = status.Errorf(codes.InvalidArgument, "unsupported x-hmac-version")
+	ErrLegacyHmacRejected  = status.Errorf(codes.Unauthenticated, "legacy signatures are no longer accepted, sign with x-hmac-version 2")
+	ErrInvalidSignedHeader = status.Errorf(codes.InvalidArgument, "x-tenant and x-idempotency-key metadata must have a single value")
+	ErrMissingTimestamp    = status.Errorf(codes.InvalidArgument, "missing or invalid x-hmac-timestamp metadata")
+	ErrMissingNonce        = status.Errorf(codes.InvalidArgument, "missing or invalid x-hmac-nonce metadata")
+	ErrStaleRequest        = status.Errorf(codes.Unauthenticated, "x-hmac-timestamp is outside the allowed clock skew")
+	ErrReplayedRequest     = status.Errorf(codes.Unauthenticated, "x-hmac-nonce was already used")
+	ErrTooManyNonces       = status.Errorf(codes.ResourceExhausted, "too many requests")
+	ErrExpiredHmacKey      = status.Errorf(codes.Unauthenticated, "x-hmac-key-id has expired")
+	ErrUnknownHmacKey      = status.Errorf(codes.Unauthenticated, "unknown x-hmac-key-id")
+	ErrHmacKeyNotYetValid  = status.Errorf(codes.Unauthenticated, "x-hmac-key-id is not valid yet")
+	ErrUnauthorized        = status.Errorf(codes.Unauthenticated, "unauthorized")
 )
 
+// keyIDContextKey is the context key of the HMAC key ID that authenticated a request
+type keyIDContextKey struct{}
+
+// KeyIDFromContext returns the HMAC key ID that authenticated the request of ctx
+func KeyIDFromContext(ctx context.Context) (string, bool) {
+	keyID, ok := ctx.Value(keyIDContextKey{}).(string)
+	return keyID, ok
+}
+
 type clientAuthInterceptor struct {
-	hmacKeyID  string
-	hmacSecret string
+	hmacKeyID    string
+	hmacSecret   string
+	signMessages bool
+}
+
+// ClientAuthOption configures the client auth interceptors
+type ClientAuthOption func(c *clientAuthInterceptor)
+
+// SignStreamMessages also signs each message sent on streams, in their
+// hmac_signature field, so they can't be injected into an authenticated stream
+func SignStreamMessages() ClientAuthOption {
+	return func(c *clientAuthInterceptor) {
+		c.signMessages = true
+	}
 }
 
 type serverAuthInterceptor struct {
-	secrets map[string]string
+	secrets      secrets.Provider
+	rejectLegacy bool
+	// legacyCutoff is when legacy signatures stop being accepted, never when zero
+	legacyCutoff time.Time
+	clockSkew    time.Duration
+	// requireSignedMessages rejects the streams whose client doesn't sign each message
+	requireSignedMessages bool
+	nonces                *nonceCache
+	now                   func() time.Time
 }
 
+// ServerAuthOption configures the server auth interceptor
+type ServerAuthOption func(s *serverAuthInterceptor)
+
+// RejectLegacySignatures only accepts requests signed with the canonical
+// scheme, once every client has migrated to it
+func RejectLegacySignatures() ServerAuthOption {
+	return func(s *serverAuthInterceptor) {
+		s.rejectLegacy = true
+	}
+}
+
+// RejectLegacySignaturesFrom only accepts requests signed with the canonical
+// scheme from cutoff on, so legacy requests, which can be replayed, stop
+// being accepted even if RejectLegacySignatures is never given
+func RejectLegacySignaturesFrom(cutoff time.Time) ServerAuthOption {
+	return func(s *serverAuthInterceptor) {
+		s.legacyCutoff = cutoff
+	}
+}
+
+// WithClockSkew sets how far the timestamp of a request may be from the
+// server clock, DefaultClockSkew by default
+func WithClockSkew(skew time.Duration) ServerAuthOption {
+	return func(s *serverAuthInterceptor) {
+		s.clockSkew = skew
+	}
+}
+
+// RequireSignedStreamMessages rejects the streams whose client doesn't sign
+// each message it sends, see SignStreamMessages, with ErrUnsignedStreamMessages
+func RequireSignedStreamMessages() ServerAuthOption {
+	return func(s *serverAuthInterceptor) {
+		s.requireSignedMessages = true
+	}
+}
+
+// NewClientAuthInterceptor signs requests with the canonical scheme, with a
+// fresh timestamp and nonce for each request
 func NewClientAuthInterceptor(hmacKeyID, hmacSecret string) grpc.UnaryClientInterceptor {
 	c := &clientAuthInterceptor{
 		hmacKeyID:  hmacKeyID,
@@ -41,18 +156,62 @@ func NewClientAuthInterceptor(hmacKeyID, hmacSecret string) grpc.UnaryClientInte
 	return c.clientInterceptor
 }
 
-func NewServerAuthInterceptor(secrets map[string]string) grpc.UnaryServerInterceptor {
+// NewClientStreamAuthInterceptor signs the opening of streams with the
+// canonical scheme, and each message sent on them with SignStreamMessages
+func NewClientStreamAuthInterceptor(hmacKeyID, hmacSecret string, opts ...ClientAuthOption) grpc.StreamClientInterceptor {
+	c := &clientAuthInterceptor{
+		hmacKeyID:  hmacKeyID,
+		hmacSecret: hmacSecret,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c.streamInterceptor
+}
+
+// NewServerAuthInterceptor authenticates requests signed by NewClientAuthInterceptor.
+// Both the canonical and the legacy signing schemes are accepted unless
+// RejectLegacySignatures is given, or until the RejectLegacySignaturesFrom
+// cutoff. Canonical requests are rejected when their timestamp is outside the
+// clock skew window or their nonce was already seen within it, legacy
+// requests aren't protected against replays and are logged with their key.
+func NewServerAuthInterceptor(provider secrets.Provider, opts ...ServerAuthOption) grpc.UnaryServerInterceptor {
+	return newServerAuthInterceptor(provider, opts...).serverInterceptor
+}
+
+// NewServerStreamAuthInterceptor authenticates streams opened by
+// NewClientStreamAuthInterceptor, only with the canonical scheme. The
+// messages received on streams whose client signs them are checked too, and
+// fail with ErrInvalidMessageSignature when altered, reordered or injected.
+// Streams with unsigned messages are rejected with RequireSignedStreamMessages.
+func NewServerStreamAuthInterceptor(provider secrets.Provider, opts ...ServerAuthOption) grpc.StreamServerInterceptor {
+	return newServerAuthInterceptor(provider, opts...).streamInterceptor
+}
+
+func newServerAuthInterceptor(provider secrets.Provider, opts ...ServerAuthOption) *serverAuthInterceptor {
 	s := &serverAuthInterceptor{
-		secrets: secrets,
+		secrets:   provider,
+		clockSkew: DefaultClockSkew,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	// A nonce is accepted again once its timestamp leaves the skew window,
+	// at most two skews after it was first seen
+	s.nonces = newNonceCache(2 * s.clockSkew)
 
-	return s.serverInterceptor
+	return s
 }
 
 func (c *clientAuthInterceptor) clientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 	//tflog.Info(ctx, "authenticating request")
-	ctx = metadata.AppendToOutgoingContext(ctx, "x-hmac-key-id", c.hmacKeyID)
-	plaintext, err := plainText(req, method)
+	ctx, headers, err := c.withFreshness(ctx)
+	if err != nil {
+		return err
+	}
+	plaintext, err := canonicalString(req, method, headers)
 
 	if err != nil {
 		return err
@@ -62,11 +221,157 @@ func (c *clientAuthInterceptor) clientInterceptor(ctx context.Context, method st
 	return invoker(ctx, method, req, reply, cc, opts...)
 }
 
+func (c *clientAuthInterceptor) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx, headers, err := c.withFreshness(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.signMessages {
+		ctx = metadata.AppendToOutgoingContext(ctx, HmacSignedMessagesHeader, "true")
+	}
+	plaintext := streamCanonicalString(method, headers, c.signMessages)
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-hmac-signature", signature(c.hmacSecret, plaintext))
+
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil || !c.signMessages {
+		return stream, err
+	}
+	return &signingClientStream{
+		ClientStream: stream,
+		method:       method,
+		nonce:        headers.nonce,
+		secret:       c.hmacSecret,
+	}, nil
+}
+
+// withFreshness adds the key ID, the canonical version, and a new timestamp
+// and nonce to the outgoing metadata of ctx, and returns the headers to sign
+func (c *clientAuthInterceptor) withFreshness(ctx context.Context) (context.Context, signedHeaders, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	headers, err := optionalSignedHeaders(md)
+	if err != nil {
+		return nil, signedHeaders{}, err
+	}
+	headers.timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+	if headers.nonce, err = newNonce(); err != nil {
+		return nil, signedHeaders{}, err
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx,
+		"x-hmac-key-id", c.hmacKeyID,
+		HmacVersionHeader, HmacVersionCanonical,
+		HmacTimestampHeader, headers.timestamp,
+		HmacNonceHeader, headers.nonce,
+	)
+	return ctx, headers, nil
+}
+
 func (s *serverAuthInterceptor) serverInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 	logger := log.With().Str("method", info.FullMethod).Logger()
 
 	logger.Debug().Msg("authenticating request")
 
+	r, err := s.signedRequest(ctx, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		plaintext string
+		headers   signedHeaders
+		legacy    bool
+	)
+	switch version := r.md[HmacVersionHeader]; {
+	case len(version) == 0 || (len(version) == 1 && version[0] == HmacVersionLegacy):
+		if s.legacyRejected() {
+			logger.Debug().Str("key", r.keyID).Msg("legacy signature rejected")
+			return nil, ErrLegacyHmacRejected
+		}
+		legacy = true
+		plaintext, err = plainText(req, info.FullMethod)
+	case len(version) == 1 && version[0] == HmacVersionCanonical:
+		if headers, err = s.checkFreshness(r.md); err != nil {
+			logger.Debug().Err(err).Str("key", r.keyID).Msg("request is not fresh")
+			return nil, err
+		}
+		plaintext, err = canonicalString(req, info.FullMethod, headers)
+	default:
+		logger.Debug().Strs("version", version).Msg("unsupported x-hmac-version metadata")
+		return nil, ErrUnsupportedHmac
+	}
+	if err != nil {
+		logger.Debug().Err(err).Msg("failed to get plaintext")
+		return nil, status.Errorf(codes.Internal, "failed to get plaintext")
+	}
+
+	if err := s.verify(logger, r, plaintext, headers.nonce); err != nil {
+		return nil, err
+	}
+	if legacy {
+		logger.Warn().Str("key", r.keyID).Msg("accepted a legacy signature, which can be replayed, sign with x-hmac-version 2")
+	}
+
+	// Call the handler to process the request
+	return handler(context.WithValue(ctx, keyIDContextKey{}, r.keyID), req)
+}
+
+func (s *serverAuthInterceptor) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	logger := log.With().Str("method", info.FullMethod).Logger()
+
+	logger.Debug().Msg("authenticating stream")
+
+	ctx := ss.Context()
+	r, err := s.signedRequest(ctx, logger)
+	if err != nil {
+		return err
+	}
+
+	// Streams were never signed with the legacy scheme
+	if version := r.md.Get(HmacVersionHeader); len(version) != 1 || version[0] != HmacVersionCanonical {
+		logger.Debug().Strs("version", version).Msg("unsupported x-hmac-version metadata")
+		return ErrUnsupportedHmac
+	}
+	headers, err := s.checkFreshness(r.md)
+	if err != nil {
+		logger.Debug().Err(err).Str("key", r.keyID).Msg("stream is not fresh")
+		return err
+	}
+	signedMessages, err := signedMessagesOf(r.md)
+	if err != nil {
+		return err
+	}
+	if !signedMessages && s.requireSignedMessages {
+		logger.Debug().Str("key", r.keyID).Msg("stream messages aren't signed")
+		return ErrUnsignedStreamMessages
+	}
+
+	if err := s.verify(logger, r, streamCanonicalString(info.FullMethod, headers, signedMessages), headers.nonce); err != nil {
+		return err
+	}
+
+	stream := &serverStream{ServerStream: ss, ctx: context.WithValue(ctx, keyIDContextKey{}, r.keyID)}
+	if !signedMessages {
+		return handler(srv, stream)
+	}
+	return handler(srv, &verifyingServerStream{
+		ServerStream: stream,
+		method:       info.FullMethod,
+		nonce:        headers.nonce,
+		secret:       r.secret,
+	})
+}
+
+// signedRequest is the HMAC metadata of a request
+type signedRequest struct {
+	md        metadata.MD
+	keyID     string
+	signature string
+	// secrets are the valid secrets of the key, secret is the one that signed the request once verified
+	secrets []string
+	secret  string
+}
+
+// signedRequest returns the HMAC metadata of the request of ctx, with the secrets of its key
+func (s *serverAuthInterceptor) signedRequest(ctx context.Context, logger zerolog.Logger) (*signedRequest, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		logger.Debug().Msg("missing metadata")
@@ -85,34 +390,120 @@ func (s *serverAuthInterceptor) serverInterceptor(ctx context.Context, req inter
 		return nil, ErrMissingHmacKeyID
 	}
 
-	secretKey, err := s.getHMACSecretKey(hmacKeyID[0])
-	if err != nil {
+	secretKeys, err := s.getHMACSecretKeys(hmacKeyID[0])
+	switch {
+	case errors.Is(err, secrets.ErrExpiredKey):
+		logger.Warn().Str("key", hmacKeyID[0]).Msg("expired HMAC key")
+		return nil, ErrExpiredHmacKey
+	case errors.Is(err, secrets.ErrKeyNotYetValid):
+		logger.Warn().Str("key", hmacKeyID[0]).Msg("HMAC key not valid yet")
+		return nil, ErrHmacKeyNotYetValid
+	case errors.Is(err, secrets.ErrUnknownKey):
+		logger.Debug().Str("key", hmacKeyID[0]).Msg("unknown HMAC key")
+		return nil, ErrUnknownHmacKey
+	case err != nil:
 		logger.Debug().Err(err).Msg("failed to get HMAC secret key")
 		return nil, status.Errorf(codes.Internal, "failed to get HMAC secret key")
 	}
 
-	plaintext, err := plainText(req, info.FullMethod)
+	return &signedRequest{
+		md:        md,
+		keyID:     hmacKeyID[0],
+		signature: hmacSign[0],
+		secrets:   secretKeys,
+	}, nil
+}
+
+// verify checks the signature of r over plaintext and remembers its nonce, if any
+func (s *serverAuthInterceptor) verify(logger zerolog.Logger, r *signedRequest, plaintext, nonce string) error {
+	// Compare HMAC signatures, with every secret valid during a rotation.
+	for _, secret := range r.secrets {
+		if hmac.Equal([]byte(r.signature), signatureBytes(secret, plaintext)) {
+			r.secret = secret
+			break
+		}
+	}
+	if r.secret == "" {
+		logger.Debug().Msg("invalid HMAC signature")
+		return status.Errorf(codes.Unauthenticated, "invalid HMAC signature")
+	}
+
+	// Only remember the nonces of authentic requests, so others can't fill the cache
+	if nonce != "" {
+		if err := s.nonces.add(r.keyID+"\x00"+nonce, s.now()); err != nil {
+			logger.Debug().Err(err).Str("key", r.keyID).Msg("nonce rejected")
+			return err
+		}
+	}
+	return nil
+}
+
+// legacyRejected tells if legacy signatures are no longer accepted
+func (s *serverAuthInterceptor) legacyRejected() bool {
+	return s.rejectLegacy || (!s.legacyCutoff.IsZero() && !s.now().Before(s.legacyCutoff))
+}
+
+// checkFreshness returns the signed headers of a canonical request, failing
+// if its timestamp is outside the clock skew window
+func (s *serverAuthInterceptor) checkFreshness(md metadata.MD) (signedHeaders, error) {
+	headers, err := optionalSignedHeaders(md)
 	if err != nil {
-		logger.Debug().Err(err).Msg("failed to get plaintext")
-		return nil, status.Errorf(codes.Internal, "failed to get plaintext")
+		return signedHeaders{}, err
+	}
+	timestamps := md.Get(HmacTimestampHeader)
+	if len(timestamps) != 1 {
+		return signedHeaders{}, ErrMissingTimestamp
+	}
+	seconds, err := strconv.ParseInt(timestamps[0], 10, 64)
+	if err != nil {
+		return signedHeaders{}, ErrMissingTimestamp
+	}
+	nonces := md.Get(HmacNonceHeader)
+	if len(nonces) != 1 || len(nonces[0]) < minNonceLength || len(nonces[0]) > maxNonceLength {
+		return signedHeaders{}, ErrMissingNonce
 	}
 
-	// Compare HMAC signatures.
-	if !hmac.Equal([]byte(hmacSign[0]), signatureBytes(secretKey, plaintext)) {
-		logger.Debug().Msg("invalid HMAC signature")
-		return nil, status.Errorf(codes.Unauthenticated, "invalid HMAC signature")
+	skew := s.now().Sub(time.Unix(seconds, 0))
+	if skew > s.clockSkew || skew < -s.clockSkew {
+		return signedHeaders{}, ErrStaleRequest
 	}
+	headers.timestamp, headers.nonce = timestamps[0], nonces[0]
+	return headers, nil
+}
 
-	// Call the handler to process the request
-	return handler(ctx, req)
+// signedHeaders are the metadata covered by canonical signatures, the
+// optional ones are empty when missing
+type signedHeaders struct {
+	timestamp      string
+	nonce          string
+	tenant         string
+	idempotencyKey string
 }
 
-func (s *serverAuthInterceptor) getHMACSecretKey(key string) (string, error) {
-	log.Debug().Str("key", key).Msg("getting HMAC secret key")
-	if secretKey, ok := s.secrets[key]; ok {
-		return secretKey, nil
+// optionalSignedHeaders returns the x-tenant and x-idempotency-key of md
+func optionalSignedHeaders(md metadata.MD) (signedHeaders, error) {
+	var headers signedHeaders
+	for _, h := range []struct {
+		key   string
+		value *string
+	}{
+		{TenantHeader, &headers.tenant},
+		{IdempotencyKeyHeader, &headers.idempotencyKey},
+	} {
+		switch values := md.Get(h.key); len(values) {
+		case 0:
+		case 1:
+			*h.value = values[0]
+		default:
+			return signedHeaders{}, ErrInvalidSignedHeader
+		}
 	}
-	return "", ErrUnauthorized
+	return headers, nil
+}
+
+func (s *serverAuthInterceptor) getHMACSecretKeys(key string) ([]string, error) {
+	log.Debug().Str("key", key).Msg("getting HMAC secret keys")
+	return s.secrets.Secrets(key)
 }
 
 func plainText(req interface{}, method string) (string, error) {
@@ -144,6 +535,84 @@ func plainText(req interface{}, method string) (string, error) {
 	return buf.String(), nil
 }
 
+// canonicalString returns the string signed by the canonical scheme, which
+// any protobuf implementation can compute:
+//
+//	"v2\n" + method + "\n" + timestamp + "\n" + nonce + "\n" + tenant + "\n" + idempotencyKey + "\n" + hex(sha256(request))
+//
+// where method is the full method name, e.g. /api.proto.v1.UserService/GetUser,
+// timestamp, nonce, tenant and idempotencyKey are the x-hmac-timestamp,
+// x-hmac-nonce, x-tenant and x-idempotency-key metadata, the last two empty
+// when missing, request is the deterministic protobuf encoding of the
+// request message, and hex is lowercase.
+func canonicalString(req interface{}, method string, headers signedHeaders) (string, error) {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return "", fmt.Errorf("request is not a protobuf message: %T", req)
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return "v2\n" + method + "\n" + headers.timestamp + "\n" + headers.nonce + "\n" +
+		headers.tenant + "\n" + headers.idempotencyKey + "\n" + hex.EncodeToString(sum[:]), nil
+}
+
+// newNonce returns a random nonce of 128 bits
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// nonceCache remembers the nonces seen during ttl
+type nonceCache struct {
+	ttl time.Duration
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+	// queue holds the seen nonces by expiry time
+	queue []seenNonce
+}
+
+type seenNonce struct {
+	nonce   string
+	expires time.Time
+}
+
+func newNonceCache(ttl time.Duration) *nonceCache {
+	return &nonceCache{
+		ttl:  ttl,
+		seen: make(map[string]struct{}),
+	}
+}
+
+// add remembers nonce, failing with ErrReplayedRequest if it was already seen
+func (c *nonceCache) add(nonce string, now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for n < len(c.queue) && now.After(c.queue[n].expires) {
+		delete(c.seen, c.queue[n].nonce)
+		n++
+	}
+	c.queue = c.queue[n:]
+
+	if _, ok := c.seen[nonce]; ok {
+		return ErrReplayedRequest
+	}
+	if len(c.queue) >= maxNonces {
+		return ErrTooManyNonces
+	}
+	c.seen[nonce] = struct{}{}
+	c.queue = append(c.queue, seenNonce{nonce: nonce, expires: now.Add(c.ttl)})
+	return nil
+}
+
 func signature(secretKey string, message string) string {
 	mac := hmac.New(sha512.New512_256, []byte(secretKey))
 	mac.Write([]byte(message))
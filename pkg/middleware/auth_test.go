@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"github.com/msharbaji/grpc-go-example/pkg/secrets"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+// TestCanonicalString checks the canonical string and its signature against
+// a vector computed outside of Go, which clients in other languages can use
+func TestCanonicalString(t *testing.T) {
+	req := &pb.CreateUserRequest{Username: "alice", Email: "alice@example.com"}
+	headers := signedHeaders{
+		timestamp:      "1700000000",
+		nonce:          "AAECAwQFBgcICQoLDA0ODw",
+		tenant:         "acme",
+		idempotencyKey: "create-alice-1",
+	}
+	got, err := canonicalString(req, pb.UserService_CreateUser_FullMethodName, headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "v2\n" +
+		"/api.proto.v1.UserService/CreateUser\n" +
+		"1700000000\n" +
+		"AAECAwQFBgcICQoLDA0ODw\n" +
+		"acme\n" +
+		"create-alice-1\n" +
+		// sha256 of 0a05616c696365 1211616c696365406578616d706c652e636f6d
+		"a46daf572388399da1fe7bc33fb977e1461a27820e6a15886423a2f4d09a59ce"
+	if got != want {
+		t.Errorf("got canonical string %q, want %q", got, want)
+	}
+	if sig, want := signature("secret", got), "MDOqu31eDraxXdED6NJSWbC+QT1RqBzuFewguZxN2pI="; sig != want {
+		t.Errorf("got signature %s, want %s", sig, want)
+	}
+
+	if _, err := canonicalString(struct{}{}, pb.UserService_CreateUser_FullMethodName, headers); err == nil {
+		t.Error("got no error for a request that isn't a protobuf message")
+	}
+}
+
+// signedMetadata returns the metadata the client interceptor sends for a
+// call of method with req, signed by key-a, on top of outgoing
+func signedMetadata(t *testing.T, method string, req interface{}, outgoing metadata.MD) metadata.MD {
+	t.Helper()
+	var md metadata.MD
+	sign := NewClientAuthInterceptor("key-a", "secret-a")
+	ctx := metadata.NewOutgoingContext(context.Background(), outgoing)
+	err := sign(ctx, method, req, nil, nil, func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return md
+}
+
+// legacyMetadata returns the metadata of a call of method with req signed
+// with the legacy scheme by key-a
+func legacyMetadata(t *testing.T, method string, req interface{}) metadata.MD {
+	t.Helper()
+	plaintext, err := plainText(req, method)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.Pairs("x-hmac-key-id", "key-a", "x-hmac-signature", signature("secret-a", plaintext))
+}
+
+// newTestAuth returns a server auth interceptor knowing key-a, whose clock is now
+func newTestAuth(now func() time.Time, opts ...ServerAuthOption) *serverAuthInterceptor {
+	s := newServerAuthInterceptor(secrets.Static{"key-a": "secret-a"}, opts...)
+	s.now = now
+	return s
+}
+
+// call runs a call of method with req and md through s
+func call(s *serverAuthInterceptor, method string, req interface{}, md metadata.MD) error {
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	_, err := s.serverInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	return err
+}
+
+func TestServerAuthReplays(t *testing.T) {
+	method := pb.UserService_CreateUser_FullMethodName
+	req := &pb.CreateUserRequest{Username: "alice", Email: "alice@example.com"}
+	fresh := func(t *testing.T) metadata.MD {
+		return signedMetadata(t, method, req, metadata.Pairs(TenantHeader, "acme", IdempotencyKeyHeader, "create-alice-1"))
+	}
+
+	for _, tc := range []struct {
+		name string
+		// md returns the metadata of the call, sent after the ones of before
+		md     func(t *testing.T) metadata.MD
+		before int
+		// skew is how far the server clock is from the client clock
+		skew time.Duration
+		opts []ServerAuthOption
+		want error
+	}{
+		{name: "fresh", md: fresh},
+		{name: "replayed nonce", md: fresh, before: 1, want: ErrReplayedRequest},
+		{name: "stale timestamp", md: fresh, skew: DefaultClockSkew + time.Minute, want: ErrStaleRequest},
+		{name: "future timestamp", md: fresh, skew: -DefaultClockSkew - time.Minute, want: ErrStaleRequest},
+		{name: "within a custom clock skew", md: fresh, skew: 9 * time.Minute, opts: []ServerAuthOption{WithClockSkew(10 * time.Minute)}},
+		{name: "missing timestamp", md: func(t *testing.T) metadata.MD {
+			md := fresh(t)
+			md.Delete(HmacTimestampHeader)
+			return md
+		}, want: ErrMissingTimestamp},
+		{name: "short nonce", md: func(t *testing.T) metadata.MD {
+			md := fresh(t)
+			md.Set(HmacNonceHeader, "short")
+			return md
+		}, want: ErrMissingNonce},
+		{name: "tampered tenant", md: func(t *testing.T) metadata.MD {
+			md := fresh(t)
+			md.Set(TenantHeader, "other")
+			return md
+		}, want: status.Error(codes.Unauthenticated, "invalid HMAC signature")},
+		{name: "added idempotency key", md: func(t *testing.T) metadata.MD {
+			md := signedMetadata(t, method, req, nil)
+			md.Set(IdempotencyKeyHeader, "create-alice-2")
+			return md
+		}, want: status.Error(codes.Unauthenticated, "invalid HMAC signature")},
+		{name: "several tenants", md: func(t *testing.T) metadata.MD {
+			md := fresh(t)
+			md.Append(TenantHeader, "other")
+			return md
+		}, want: ErrInvalidSignedHeader},
+		{name: "legacy", md: func(t *testing.T) metadata.MD {
+			return legacyMetadata(t, method, req)
+		}},
+		{name: "legacy rejected", md: func(t *testing.T) metadata.MD {
+			return legacyMetadata(t, method, req)
+		}, opts: []ServerAuthOption{RejectLegacySignatures()}, want: ErrLegacyHmacRejected},
+		{name: "legacy before the cutoff", md: func(t *testing.T) metadata.MD {
+			return legacyMetadata(t, method, req)
+		}, opts: []ServerAuthOption{RejectLegacySignaturesFrom(time.Now().Add(time.Hour))}},
+		{name: "legacy after the cutoff", md: func(t *testing.T) metadata.MD {
+			return legacyMetadata(t, method, req)
+		}, opts: []ServerAuthOption{RejectLegacySignaturesFrom(time.Now().Add(-time.Hour))}, want: ErrLegacyHmacRejected},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestAuth(func() time.Time { return time.Now().Add(tc.skew) }, tc.opts...)
+			md := tc.md(t)
+			for i := 0; i < tc.before; i++ {
+				if err := call(s, method, req, md); err != nil {
+					t.Fatalf("got %v on call %d", err, i)
+				}
+			}
+			err := call(s, method, req, md)
+			if status.Code(err) != status.Code(tc.want) || status.Convert(err).Message() != status.Convert(tc.want).Message() {
+				t.Errorf("got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestNonceCacheExpiry(t *testing.T) {
+	const ttl = 10 * time.Minute
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newNonceCache(ttl)
+
+	for _, tc := range []struct {
+		name  string
+		nonce string
+		at    time.Time
+		want  error
+	}{
+		{"first use", "nonce-a", start, nil},
+		{"other nonce", "nonce-b", start.Add(time.Minute), nil},
+		{"replay", "nonce-a", start.Add(time.Minute), ErrReplayedRequest},
+		{"replay at expiry", "nonce-a", start.Add(ttl), ErrReplayedRequest},
+		{"after expiry", "nonce-a", start.Add(ttl + time.Nanosecond), nil},
+		{"replay of the other nonce", "nonce-b", start.Add(ttl + time.Nanosecond), ErrReplayedRequest},
+		{"replay after reuse", "nonce-a", start.Add(ttl + time.Second), ErrReplayedRequest},
+	} {
+		if err := c.add(tc.nonce, tc.at); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+	if len(c.seen) != 2 || len(c.queue) != 2 {
+		t.Errorf("got %d seen and %d queued nonces, want the expired one forgotten", len(c.seen), len(c.queue))
+	}
+}
+
+// failingProvider fails to provide any secret with err
+type failingProvider struct {
+	err error
+}
+
+func (p failingProvider) Secrets(string) ([]string, error) {
+	return nil, p.err
+}
+
+func TestServerAuthKeyErrors(t *testing.T) {
+	method := pb.UserService_CreateUser_FullMethodName
+	req := &pb.CreateUserRequest{Username: "alice", Email: "alice@example.com"}
+
+	for _, tc := range []struct {
+		err  error
+		want error
+	}{
+		{secrets.ErrUnknownKey, ErrUnknownHmacKey},
+		{fmt.Errorf("wrapped: %w", secrets.ErrUnknownKey), ErrUnknownHmacKey},
+		{secrets.ErrExpiredKey, ErrExpiredHmacKey},
+		{secrets.ErrKeyNotYetValid, ErrHmacKeyNotYetValid},
+		{errors.New("vault is down"), status.Error(codes.Internal, "failed to get HMAC secret key")},
+	} {
+		s := newServerAuthInterceptor(failingProvider{tc.err})
+		err := call(s, method, req, signedMetadata(t, method, req, nil))
+		if status.Code(err) != status.Code(tc.want) || status.Convert(err).Message() != status.Convert(tc.want).Message() {
+			t.Errorf("got %v for a provider failing with %v, want %v", err, tc.err, tc.want)
+		}
+	}
+}
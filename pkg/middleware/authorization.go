@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/pkg/apierrors"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Permission grants access to a group of methods
+type Permission string
+
+const (
+	PermissionReadVersion Permission = "version.read"
+	PermissionReadUsers   Permission = "users.read"
+	PermissionWriteUsers  Permission = "users.write"
+	PermissionDeleteUsers Permission = "users.delete"
+)
+
+// Role is a named set of permissions given to HMAC keys
+type Role string
+
+const (
+	// RoleViewer can only read, it is the role of keys without one
+	RoleViewer Role = "viewer"
+	// RoleEditor can read, create and update
+	RoleEditor Role = "editor"
+	// RoleOwner can do everything
+	RoleOwner Role = "owner"
+)
+
+// rolePermissions are the permissions of each role
+var rolePermissions = map[Role][]Permission{
+	RoleViewer: {PermissionReadVersion, PermissionReadUsers},
+	RoleEditor: {PermissionReadVersion, PermissionReadUsers, PermissionWriteUsers},
+	RoleOwner:  {PermissionReadVersion, PermissionReadUsers, PermissionWriteUsers, PermissionDeleteUsers},
+}
+
+// ParseRole returns the role named name
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := rolePermissions[role]; !ok {
+		return "", fmt.Errorf("unknown role: %s", name)
+	}
+	return role, nil
+}
+
+// RoleProvider returns the role of HMAC key IDs
+type RoleProvider interface {
+	// Role returns the role of keyID, false when it has none
+	Role(keyID string) (Role, bool)
+}
+
+// Roles maps HMAC key IDs to their role
+type Roles map[string]Role
+
+// Role returns the role of keyID
+func (r Roles) Role(keyID string) (Role, bool) {
+	role, ok := r[keyID]
+	return role, ok
+}
+
+type authorizationInterceptor struct {
+	roles   RoleProvider
+	methods map[string][]Permission
+}
+
+// NewServerAuthorizationInterceptor only lets the HMAC keys whose role has
+// every permission required by a method call it. roles gives key IDs their
+// role, RoleViewer when they have none. methods maps full method names to the
+// permissions they require, methods missing from it are denied. Denied calls
+// fail with codes.PermissionDenied, see apierrors.MissingPermission. It must
+// be chained after the auth interceptor.
+func NewServerAuthorizationInterceptor(roles RoleProvider, methods map[string][]Permission) grpc.UnaryServerInterceptor {
+	a := &authorizationInterceptor{
+		roles:   roles,
+		methods: methods,
+	}
+	return a.serverInterceptor
+}
+
+// NewServerStreamAuthorizationInterceptor is NewServerAuthorizationInterceptor for streams
+func NewServerStreamAuthorizationInterceptor(roles RoleProvider, methods map[string][]Permission) grpc.StreamServerInterceptor {
+	a := &authorizationInterceptor{
+		roles:   roles,
+		methods: methods,
+	}
+	return a.streamInterceptor
+}
+
+func (a *authorizationInterceptor) serverInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authorizationInterceptor) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authorize(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// authorize fails if the key that authenticated ctx can't call method
+func (a *authorizationInterceptor) authorize(ctx context.Context, method string) error {
+	keyID, ok := KeyIDFromContext(ctx)
+	if !ok {
+		return ErrUnauthorized
+	}
+	role, ok := a.roles.Role(keyID)
+	if !ok {
+		role = RoleViewer
+	}
+
+	required, ok := a.methods[method]
+	if !ok {
+		log.Debug().Str("method", method).Str("key", keyID).Msg("method has no permissions, denying")
+		return status.Errorf(codes.PermissionDenied, "method %s is not allowed", method)
+	}
+	for _, permission := range required {
+		if !role.has(permission) {
+			log.Debug().Str("method", method).Str("key", keyID).Str("role", string(role)).Str("permission", string(permission)).Msg("permission denied")
+			return apierrors.NewPermissionDenied(
+				fmt.Sprintf("key %s with role %s is missing permission %s", keyID, role, permission),
+				string(permission),
+			)
+		}
+	}
+	return nil
+}
+
+// has tells if the role grants permission
+func (r Role) has(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
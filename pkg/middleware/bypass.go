@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc"
+	"strings"
+)
+
+// MethodMatcher matches full method names against a list of patterns, either
+// a full method name such as /api.proto.v1.VersionService/GetVersion, or a
+// service wildcard such as /grpc.health.v1.Health/* matching all its methods
+type MethodMatcher struct {
+	methods  map[string]bool
+	services map[string]bool
+}
+
+// NewMethodMatcher creates a matcher of the given patterns
+func NewMethodMatcher(patterns ...string) (*MethodMatcher, error) {
+	m := &MethodMatcher{
+		methods:  make(map[string]bool),
+		services: make(map[string]bool),
+	}
+	for _, pattern := range patterns {
+		service, method, ok := splitMethod(pattern)
+		switch {
+		case !ok || strings.Contains(service, "*") || (method != "*" && strings.Contains(method, "*")):
+			return nil, fmt.Errorf("invalid method pattern %q, want /package.Service/Method or /package.Service/*", pattern)
+		case method == "*":
+			m.services[service] = true
+		default:
+			m.methods[pattern] = true
+		}
+	}
+	return m, nil
+}
+
+// Match tells if fullMethod matches one of the patterns
+func (m *MethodMatcher) Match(fullMethod string) bool {
+	if m == nil {
+		return false
+	}
+	if m.methods[fullMethod] {
+		return true
+	}
+	service, _, ok := splitMethod(fullMethod)
+	return ok && m.services[service]
+}
+
+// splitMethod splits /service/method into its service and method
+func splitMethod(fullMethod string) (service, method string, ok bool) {
+	if !strings.HasPrefix(fullMethod, "/") {
+		return "", "", false
+	}
+	service, method, ok = strings.Cut(fullMethod[1:], "/")
+	if !ok || service == "" || method == "" || strings.Contains(method, "/") {
+		return "", "", false
+	}
+	return service, method, true
+}
+
+// NewServerBypassInterceptor runs interceptors, in order, except for the
+// methods matched by public which go straight to the next interceptor. It
+// lets public methods skip the auth, authorization and tenant interceptors,
+// every other method still goes through them.
+func NewServerBypassInterceptor(public *MethodMatcher, interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if public.Match(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, h := interceptors[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, h)
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// NewServerStreamBypassInterceptor is NewServerBypassInterceptor for streams
+func NewServerStreamBypassInterceptor(public *MethodMatcher, interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if public.Match(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, h := interceptors[i], next
+			next = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, h)
+			}
+		}
+		return next(srv, ss)
+	}
+}
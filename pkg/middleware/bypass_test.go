@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestMethodMatcher(t *testing.T) {
+	m, err := NewMethodMatcher(
+		"/grpc.health.v1.Health/*",
+		"/api.proto.v1.VersionService/GetVersion",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for method, want := range map[string]bool{
+		"/grpc.health.v1.Health/Check":                 true,
+		"/grpc.health.v1.Health/Watch":                 true,
+		"/api.proto.v1.VersionService/GetVersion":      true,
+		"/api.proto.v1.VersionService/GetOther":        false,
+		"/api.proto.v1.UserService/GetUser":            false,
+		"/grpc.health.v1.HealthX/Check":                false,
+		"/grpc.health.v1/Health/Check":                 false,
+		"/grpc.health.v1.Health/":                      false,
+		"/grpc.health.v1.Health":                       false,
+		"grpc.health.v1.Health/Check":                  false,
+		"":                                             false,
+		"/api.proto.v1.VersionService/GetVersion/more": false,
+	} {
+		if got := m.Match(method); got != want {
+			t.Errorf("got %v matching %q, want %v", got, method, want)
+		}
+	}
+
+	var none *MethodMatcher
+	if none.Match("/grpc.health.v1.Health/Check") {
+		t.Error("a nil matcher matched a method")
+	}
+}
+
+func TestNewMethodMatcherErrors(t *testing.T) {
+	for _, pattern := range []string{
+		"",
+		"*",
+		"/*",
+		"/*/*",
+		"/grpc.health.*/Check",
+		"/grpc.health.v1.Health/Ch*",
+		"grpc.health.v1.Health/*",
+		"/grpc.health.v1.Health",
+		"/grpc.health.v1.Health/Check/*",
+	} {
+		if _, err := NewMethodMatcher(pattern); err == nil {
+			t.Errorf("got no error for pattern %q", pattern)
+		}
+	}
+}
+
+func TestServerBypassInterceptor(t *testing.T) {
+	public, err := NewMethodMatcher("/grpc.health.v1.Health/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	record := func(name string, err error) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}
+	}
+	interceptor := NewServerBypassInterceptor(public, record("auth", nil), record("authorization", ErrUnauthorized))
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		calls = append(calls, "handler")
+		return nil, nil
+	}
+
+	for _, tc := range []struct {
+		method string
+		calls  string
+		want   codes.Code
+	}{
+		{"/grpc.health.v1.Health/Check", "[handler]", codes.OK},
+		{"/api.proto.v1.UserService/GetUser", "[auth authorization]", codes.Unauthenticated},
+	} {
+		calls = nil
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+		if status.Code(err) != tc.want {
+			t.Errorf("got %v calling %s, want %v", err, tc.method, tc.want)
+		}
+		if got := fmt.Sprint(calls); got != tc.calls {
+			t.Errorf("got calls %s for %s, want %s", got, tc.method, tc.calls)
+		}
+	}
+}
+
+func TestServerStreamBypassInterceptor(t *testing.T) {
+	public, err := NewMethodMatcher("/grpc.reflection.v1.ServerReflection/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	deny := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		calls = append(calls, "auth")
+		return ErrUnauthorized
+	}
+	interceptor := NewServerStreamBypassInterceptor(public, deny)
+	handler := func(interface{}, grpc.ServerStream) error {
+		calls = append(calls, "handler")
+		return nil
+	}
+
+	for _, tc := range []struct {
+		method string
+		calls  string
+		want   codes.Code
+	}{
+		{"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", "[handler]", codes.OK},
+		{"/api.proto.v1.UserService/WatchUsers", "[auth]", codes.Unauthenticated},
+	} {
+		calls = nil
+		err := interceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: tc.method}, handler)
+		if status.Code(err) != tc.want {
+			t.Errorf("got %v calling %s, want %v", err, tc.method, tc.want)
+		}
+		if got := fmt.Sprint(calls); got != tc.calls {
+			t.Errorf("got calls %s for %s, want %s", got, tc.method, tc.calls)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"github.com/msharbaji/grpc-go-example/pkg/tenant"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader is the metadata key of the idempotency key of a request
+	IdempotencyKeyHeader = "x-idempotency-key"
+	// IdempotentReplayHeader is set to "true" on responses replayed from an earlier call
+	IdempotentReplayHeader = "x-idempotent-replay"
+
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentCalls bounds the memory used by the cache, the oldest calls are forgotten first
+	maxIdempotentCalls = 100000
+)
+
+var (
+	ErrInvalidIdempotencyKey = status.Errorf(codes.InvalidArgument, "invalid x-idempotency-key metadata")
+	ErrIdempotencyKeyReused  = status.Errorf(codes.InvalidArgument, "x-idempotency-key was already used for a different request")
+)
+
+type idempotencyInterceptor struct {
+	window  time.Duration
+	methods map[string]bool
+
+	mu    sync.Mutex
+	calls map[idempotencyKey]*idempotentCall
+	// completed holds the completed calls by expiry time
+	completed []*idempotentCall
+}
+
+// idempotencyKey scopes idempotency keys to the HMAC key ID that sent them,
+// so clients can't replay each other's responses, and to their tenant
+type idempotencyKey struct {
+	keyID  string
+	tenant string
+	key    string
+}
+
+// idempotentCall is the outcome of the first call made with an idempotency key
+type idempotentCall struct {
+	key         idempotencyKey
+	fingerprint [sha256.Size]byte
+	// done is closed once res and err are set
+	done    chan struct{}
+	res     interface{}
+	err     error
+	expires time.Time
+}
+
+// NewServerIdempotencyInterceptor makes the given methods idempotent for
+// requests carrying an x-idempotency-key metadata: the response of the first
+// call is replayed to the calls made with the same key and HMAC key ID during
+// window. Reusing a key for a different request fails with
+// ErrIdempotencyKeyReused. Failures that may succeed when retried aren't
+// replayed. It must be chained after the auth and tenant interceptors.
+func NewServerIdempotencyInterceptor(window time.Duration, methods ...string) grpc.UnaryServerInterceptor {
+	i := &idempotencyInterceptor{
+		window:  window,
+		methods: make(map[string]bool, len(methods)),
+		calls:   make(map[idempotencyKey]*idempotentCall),
+	}
+	for _, method := range methods {
+		i.methods[method] = true
+	}
+	return i.serverInterceptor
+}
+
+func (i *idempotencyInterceptor) serverInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !i.methods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	keys := md.Get(IdempotencyKeyHeader)
+	if len(keys) == 0 {
+		return handler(ctx, req)
+	}
+	if len(keys) != 1 || keys[0] == "" || len(keys[0]) > maxIdempotencyKeyLength {
+		return nil, ErrInvalidIdempotencyKey
+	}
+
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return handler(ctx, req)
+	}
+	fingerprint, err := requestFingerprint(info.FullMethod, msg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fingerprint request")
+	}
+
+	keyID, _ := KeyIDFromContext(ctx)
+	tenantID, _ := tenant.FromContext(ctx)
+	key := idempotencyKey{keyID: keyID, tenant: tenantID, key: keys[0]}
+
+	i.mu.Lock()
+	i.expire(time.Now())
+	call, replay := i.calls[key]
+	if !replay {
+		call = &idempotentCall{key: key, fingerprint: fingerprint, done: make(chan struct{})}
+		i.calls[key] = call
+	}
+	i.mu.Unlock()
+
+	if replay {
+		if call.fingerprint != fingerprint {
+			return nil, ErrIdempotencyKeyReused
+		}
+		// Wait for the first call if it's still running
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		log.Debug().Str("method", info.FullMethod).Str("key", key.key).Msg("replaying idempotent call")
+		_ = grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayHeader, "true"))
+		if res, ok := call.res.(proto.Message); ok {
+			return proto.Clone(res), call.err
+		}
+		return call.res, call.err
+	}
+
+	call.res, call.err = handler(ctx, req)
+
+	i.mu.Lock()
+	if retryable(call.err) {
+		delete(i.calls, key)
+	} else {
+		call.expires = time.Now().Add(i.window)
+		i.completed = append(i.completed, call)
+	}
+	i.mu.Unlock()
+	close(call.done)
+
+	return call.res, call.err
+}
+
+// expire forgets the calls completed before now minus the window, and the
+// oldest ones beyond maxIdempotentCalls. The caller must hold mu.
+func (i *idempotencyInterceptor) expire(now time.Time) {
+	n := 0
+	for n < len(i.completed) && (now.After(i.completed[n].expires) || len(i.completed)-n > maxIdempotentCalls) {
+		delete(i.calls, i.completed[n].key)
+		i.completed[n] = nil
+		n++
+	}
+	i.completed = i.completed[n:]
+}
+
+// requestFingerprint identifies the method and content of a request
+func requestFingerprint(method string, req proto.Message) ([sha256.Size]byte, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(append([]byte(method+"\x00"), data...)), nil
+}
+
+// retryable tells if a failed call may succeed when made again, it is then
+// executed again rather than replayed
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded, codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.Internal, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
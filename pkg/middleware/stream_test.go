@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"context"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"testing"
+	"time"
+)
+
+// sentStream is a client stream recording the messages sent on it
+type sentStream struct {
+	grpc.ClientStream
+	sent []*pb.SyncUsersRequest
+}
+
+func (s *sentStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m.(*pb.SyncUsersRequest))
+	return nil
+}
+
+// receivedStream is a server stream receiving msgs with the incoming metadata md
+type receivedStream struct {
+	grpc.ServerStream
+	md   metadata.MD
+	msgs []*pb.SyncUsersRequest
+}
+
+func (s *receivedStream) Context() context.Context {
+	return metadata.NewIncomingContext(context.Background(), s.md)
+}
+
+func (s *receivedStream) RecvMsg(m interface{}) error {
+	if len(s.msgs) == 0 {
+		return io.EOF
+	}
+	proto.Merge(m.(*pb.SyncUsersRequest), s.msgs[0])
+	s.msgs = s.msgs[1:]
+	return nil
+}
+
+// openStream opens a stream of method signed by key-a, sends msgs on it and
+// returns its metadata and the messages as sent
+func openStream(t *testing.T, method string, msgs []*pb.SyncUsersRequest, opts ...ClientAuthOption) (metadata.MD, []*pb.SyncUsersRequest) {
+	t.Helper()
+	var md metadata.MD
+	sent := &sentStream{}
+	open := NewClientStreamAuthInterceptor("key-a", "secret-a", opts...)
+	stream, err := open(context.Background(), &grpc.StreamDesc{ClientStreams: true}, nil, method,
+		func(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+			md, _ = metadata.FromOutgoingContext(ctx)
+			return sent, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range msgs {
+		if err := stream.SendMsg(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return md, sent.sent
+}
+
+// serveStream runs a SyncUsers stream with md and msgs through s, and returns
+// the messages the handler received and the error it ended with
+func serveStream(s *serverAuthInterceptor, md metadata.MD, msgs []*pb.SyncUsersRequest) ([]*pb.SyncUsersRequest, error) {
+	var received []*pb.SyncUsersRequest
+	err := s.streamInterceptor(nil, &receivedStream{md: md, msgs: msgs}, &grpc.StreamServerInfo{FullMethod: pb.UserService_SyncUsers_FullMethodName},
+		func(_ interface{}, ss grpc.ServerStream) error {
+			for {
+				msg := &pb.SyncUsersRequest{}
+				if err := ss.RecvMsg(msg); err == io.EOF {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				received = append(received, msg)
+			}
+		})
+	return received, err
+}
+
+func syncRequest(username string) *pb.SyncUsersRequest {
+	return &pb.SyncUsersRequest{User: &pb.CreateUserRequest{Username: username, Email: username + "@example.com"}}
+}
+
+func TestStreamOpenSignature(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		md   func(t *testing.T) metadata.MD
+		opts []ServerAuthOption
+		want error
+	}{
+		{name: "signed", md: func(t *testing.T) metadata.MD {
+			md, _ := openStream(t, pb.UserService_SyncUsers_FullMethodName, nil)
+			return md
+		}},
+		{name: "other method", md: func(t *testing.T) metadata.MD {
+			md, _ := openStream(t, pb.UserService_WatchUsers_FullMethodName, nil)
+			return md
+		}, want: status.Error(codes.Unauthenticated, "invalid HMAC signature")},
+		{name: "signed messages header removed", md: func(t *testing.T) metadata.MD {
+			md, _ := openStream(t, pb.UserService_SyncUsers_FullMethodName, nil, SignStreamMessages())
+			md.Delete(HmacSignedMessagesHeader)
+			return md
+		}, want: status.Error(codes.Unauthenticated, "invalid HMAC signature")},
+		{name: "tampered tenant", md: func(t *testing.T) metadata.MD {
+			md, _ := openStream(t, pb.UserService_SyncUsers_FullMethodName, nil)
+			md.Set(TenantHeader, "other")
+			return md
+		}, want: status.Error(codes.Unauthenticated, "invalid HMAC signature")},
+		{name: "legacy", md: func(t *testing.T) metadata.MD {
+			md, _ := openStream(t, pb.UserService_SyncUsers_FullMethodName, nil)
+			md.Delete(HmacVersionHeader)
+			return md
+		}, want: ErrUnsupportedHmac},
+		{name: "unsigned messages required", md: func(t *testing.T) metadata.MD {
+			md, _ := openStream(t, pb.UserService_SyncUsers_FullMethodName, nil)
+			return md
+		}, opts: []ServerAuthOption{RequireSignedStreamMessages()}, want: ErrUnsignedStreamMessages},
+		{name: "signed messages required", md: func(t *testing.T) metadata.MD {
+			md, _ := openStream(t, pb.UserService_SyncUsers_FullMethodName, nil, SignStreamMessages())
+			return md
+		}, opts: []ServerAuthOption{RequireSignedStreamMessages()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := serveStream(newTestAuth(time.Now, tc.opts...), tc.md(t), nil)
+			if status.Code(err) != status.Code(tc.want) || status.Convert(err).Message() != status.Convert(tc.want).Message() {
+				t.Errorf("got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestStreamMessageSignatures(t *testing.T) {
+	msgs := []*pb.SyncUsersRequest{syncRequest("alice"), syncRequest("bob"), syncRequest("carol")}
+
+	for _, tc := range []struct {
+		name string
+		// change alters the messages as sent
+		change func(sent []*pb.SyncUsersRequest) []*pb.SyncUsersRequest
+		// want is the number of messages received before failing, all when ok
+		want int
+		ok   bool
+	}{
+		{name: "signed", change: func(sent []*pb.SyncUsersRequest) []*pb.SyncUsersRequest { return sent }, want: 3, ok: true},
+		{name: "tampered", change: func(sent []*pb.SyncUsersRequest) []*pb.SyncUsersRequest {
+			sent[1].User.Email = "mallory@example.com"
+			return sent
+		}, want: 1},
+		{name: "out of order", change: func(sent []*pb.SyncUsersRequest) []*pb.SyncUsersRequest {
+			return []*pb.SyncUsersRequest{sent[0], sent[2], sent[1]}
+		}, want: 1},
+		{name: "dropped", change: func(sent []*pb.SyncUsersRequest) []*pb.SyncUsersRequest {
+			return []*pb.SyncUsersRequest{sent[0], sent[2]}
+		}, want: 1},
+		{name: "injected unsigned", change: func(sent []*pb.SyncUsersRequest) []*pb.SyncUsersRequest {
+			return append(sent, syncRequest("mallory"))
+		}, want: 3},
+		{name: "replayed", change: func(sent []*pb.SyncUsersRequest) []*pb.SyncUsersRequest {
+			return append(sent, proto.Clone(sent[0]).(*pb.SyncUsersRequest))
+		}, want: 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			md, sent := openStream(t, pb.UserService_SyncUsers_FullMethodName, msgs, SignStreamMessages())
+			for i, msg := range sent {
+				if msg.GetHmacSignature() == "" {
+					t.Fatalf("message %d was sent unsigned", i)
+				}
+			}
+			if msgs[0].GetHmacSignature() != "" {
+				t.Fatal("signing changed the caller's message")
+			}
+
+			received, err := serveStream(newTestAuth(time.Now), md, tc.change(sent))
+			if tc.ok && err != nil {
+				t.Fatal(err)
+			}
+			if !tc.ok && err != ErrInvalidMessageSignature {
+				t.Errorf("got %v, want ErrInvalidMessageSignature", err)
+			}
+			if len(received) != tc.want {
+				t.Fatalf("got %d messages, want %d", len(received), tc.want)
+			}
+			for i, msg := range received {
+				if !proto.Equal(msg, msgs[i]) {
+					t.Errorf("got message %d %v, want %v without its signature", i, msg, msgs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"github.com/msharbaji/grpc-go-example/pkg/tenant"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TenantHeader is the metadata key admin keys use to pick the tenant of a request
+const TenantHeader = "x-tenant"
+
+// TenantProvider returns the tenant of HMAC key IDs
+type TenantProvider interface {
+	// Tenant returns the tenant keyID acts in, false when it has none
+	Tenant(keyID string) (string, bool)
+}
+
+// Tenants maps HMAC key IDs to the tenant they act in
+type Tenants map[string]string
+
+// Tenant returns the tenant of keyID
+func (t Tenants) Tenant(keyID string) (string, bool) {
+	name, ok := t[keyID]
+	return name, ok
+}
+
+type tenantInterceptor struct {
+	tenants   TenantProvider
+	adminKeys map[string]bool
+}
+
+// NewServerTenantInterceptor sets the tenant of requests in their context, see
+// tenant.FromContext. It is the tenant of the HMAC key ID that authenticated
+// the request, tenant.Default for keys without one. Admin keys can act in any
+// tenant by sending it in x-tenant metadata, other keys sending another tenant
+// than theirs fail with codes.PermissionDenied. It must be chained after the
+// auth interceptor.
+func NewServerTenantInterceptor(tenants TenantProvider, adminKeys []string) grpc.UnaryServerInterceptor {
+	return newTenantInterceptor(tenants, adminKeys).serverInterceptor
+}
+
+// NewServerStreamTenantInterceptor is NewServerTenantInterceptor for streams
+func NewServerStreamTenantInterceptor(tenants TenantProvider, adminKeys []string) grpc.StreamServerInterceptor {
+	return newTenantInterceptor(tenants, adminKeys).streamInterceptor
+}
+
+func newTenantInterceptor(tenants TenantProvider, adminKeys []string) *tenantInterceptor {
+	t := &tenantInterceptor{
+		tenants:   tenants,
+		adminKeys: make(map[string]bool, len(adminKeys)),
+	}
+	for _, keyID := range adminKeys {
+		t.adminKeys[keyID] = true
+	}
+	return t
+}
+
+func (t *tenantInterceptor) serverInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := t.withTenant(ctx)
+	if err != nil {
+		log.Debug().Str("method", info.FullMethod).Err(err).Msg("invalid tenant")
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (t *tenantInterceptor) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := t.withTenant(ss.Context())
+	if err != nil {
+		log.Debug().Str("method", info.FullMethod).Err(err).Msg("invalid tenant")
+		return err
+	}
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// withTenant returns ctx acting in the tenant of its request
+func (t *tenantInterceptor) withTenant(ctx context.Context) (context.Context, error) {
+	keyID, ok := KeyIDFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+	own, ok := t.tenants.Tenant(keyID)
+	if !ok || own == "" {
+		own = tenant.Default
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	requested := md.Get(TenantHeader)
+	switch {
+	case len(requested) == 0 || requested[0] == own:
+		return tenant.NewContext(ctx, own), nil
+	case len(requested) > 1 || requested[0] == "":
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s metadata", TenantHeader)
+	case !t.adminKeys[keyID]:
+		return nil, status.Errorf(codes.PermissionDenied, "key %s can't act in tenant %s", keyID, requested[0])
+	default:
+		return tenant.NewContext(ctx, requested[0]), nil
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"github.com/msharbaji/grpc-go-example/pkg/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestTenantInterceptor(t *testing.T) {
+	interceptor := NewServerTenantInterceptor(Tenants{"acme-key": "acme", "admin-key": "ops"}, []string{"admin-key"})
+
+	for _, tc := range []struct {
+		name      string
+		keyID     string
+		requested []string
+		want      string
+		wantCode  codes.Code
+	}{
+		{name: "own tenant", keyID: "acme-key", want: "acme"},
+		{name: "key without tenant", keyID: "other-key", want: tenant.Default},
+		{name: "own tenant requested", keyID: "acme-key", requested: []string{"acme"}, want: "acme"},
+		{name: "other tenant requested", keyID: "acme-key", requested: []string{"globex"}, wantCode: codes.PermissionDenied},
+		{name: "default tenant requested", keyID: "acme-key", requested: []string{tenant.Default}, wantCode: codes.PermissionDenied},
+		{name: "key without tenant requesting one", keyID: "other-key", requested: []string{"acme"}, wantCode: codes.PermissionDenied},
+		{name: "admin", keyID: "admin-key", want: "ops"},
+		{name: "admin requesting a tenant", keyID: "admin-key", requested: []string{"globex"}, want: "globex"},
+		{name: "admin requesting an empty tenant", keyID: "admin-key", requested: []string{""}, wantCode: codes.InvalidArgument},
+		{name: "admin requesting several tenants", keyID: "admin-key", requested: []string{"acme", "globex"}, wantCode: codes.InvalidArgument},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), keyIDContextKey{}, tc.keyID)
+			md := metadata.MD{}
+			for _, requested := range tc.requested {
+				md.Append(TenantHeader, requested)
+			}
+			ctx = metadata.NewIncomingContext(ctx, md)
+
+			var got string
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/api.proto.v1.UserService/GetUser"}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+				got, _ = tenant.FromContext(ctx)
+				return nil, nil
+			})
+			if status.Code(err) != tc.wantCode {
+				t.Fatalf("got %v, want %v", err, tc.wantCode)
+			}
+			if got != tc.want {
+				t.Errorf("got tenant %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, nil); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("got %v without an authenticated key, want Unauthenticated", err)
+	}
+}
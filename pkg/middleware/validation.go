@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"github.com/msharbaji/grpc-go-example/pkg/validate"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// NewServerValidationInterceptor rejects requests that break the rules
+// declared on their fields with codes.InvalidArgument and a google.rpc.BadRequest
+func NewServerValidationInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if msg, ok := req.(proto.Message); ok {
+			if err := validate.Validate(msg); err != nil {
+				log.Debug().Str("method", info.FullMethod).Err(err).Msg("invalid request")
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewClientValidationInterceptor checks requests against the rules declared
+// on their fields before sending them, failing like the server would
+func NewClientValidationInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if msg, ok := req.(proto.Message); ok {
+			if err := validate.Validate(msg); err != nil {
+				return err
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -11,7 +11,6 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
-	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -20,11 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	UserService_CreateUser_FullMethodName = "/api.proto.v1.UserService/CreateUser"
-	UserService_GetUser_FullMethodName    = "/api.proto.v1.UserService/GetUser"
-	UserService_UpdateUser_FullMethodName = "/api.proto.v1.UserService/UpdateUser"
-	UserService_DeleteUser_FullMethodName = "/api.proto.v1.UserService/DeleteUser"
-	UserService_ListUsers_FullMethodName  = "/api.proto.v1.UserService/ListUsers"
+	UserService_CreateUser_FullMethodName      = "/api.proto.v1.UserService/CreateUser"
+	UserService_GetUser_FullMethodName         = "/api.proto.v1.UserService/GetUser"
+	UserService_BatchGetUsers_FullMethodName   = "/api.proto.v1.UserService/BatchGetUsers"
+	UserService_UpdateUser_FullMethodName      = "/api.proto.v1.UserService/UpdateUser"
+	UserService_DeleteUser_FullMethodName      = "/api.proto.v1.UserService/DeleteUser"
+	UserService_UndeleteUser_FullMethodName    = "/api.proto.v1.UserService/UndeleteUser"
+	UserService_ListUsers_FullMethodName       = "/api.proto.v1.UserService/ListUsers"
+	UserService_WatchUsers_FullMethodName      = "/api.proto.v1.UserService/WatchUsers"
+	UserService_BulkCreateUsers_FullMethodName = "/api.proto.v1.UserService/BulkCreateUsers"
+	UserService_SyncUsers_FullMethodName       = "/api.proto.v1.UserService/SyncUsers"
 )
 
 // UserServiceClient is the client API for UserService service.
@@ -33,9 +37,27 @@ const (
 type UserServiceClient interface {
 	CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*CreateUserResponse, error)
 	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*GetUserResponse, error)
+	// BatchGetUsers gets several users in one call
+	BatchGetUsers(ctx context.Context, in *BatchGetUsersRequest, opts ...grpc.CallOption) (*BatchGetUsersResponse, error)
 	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UpdateUserResponse, error)
+	// DeleteUser soft-deletes a user: it is hidden until undeleted, and purged
+	// after a retention period. Its username and email stay taken until then:
+	// reusing them fails with ALREADY_EXISTS whose ErrorInfo has deleted=true.
 	DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error)
-	ListUsers(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListUsersResponse, error)
+	// UndeleteUser restores a soft-deleted user that wasn't purged yet.
+	UndeleteUser(ctx context.Context, in *UndeleteUserRequest, opts ...grpc.CallOption) (*UndeleteUserResponse, error)
+	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error)
+	// WatchUsers streams user changes. Slow consumers are disconnected with
+	// RESOURCE_EXHAUSTED and should resume from the last revision they got,
+	// OUT_OF_RANGE means that revision is no longer retained or comes from a
+	// previous run of the server.
+	WatchUsers(ctx context.Context, in *WatchUsersRequest, opts ...grpc.CallOption) (UserService_WatchUsersClient, error)
+	// BulkCreateUsers creates the users streamed by the client and reports the
+	// outcome of each one.
+	BulkCreateUsers(ctx context.Context, opts ...grpc.CallOption) (UserService_BulkCreateUsersClient, error)
+	// SyncUsers reconciles users with the desired users streamed by the client,
+	// matched by username, and streams back the action taken for each one.
+	SyncUsers(ctx context.Context, opts ...grpc.CallOption) (UserService_SyncUsersClient, error)
 }
 
 type userServiceClient struct {
@@ -64,6 +86,15 @@ func (c *userServiceClient) GetUser(ctx context.Context, in *GetUserRequest, opt
 	return out, nil
 }
 
+func (c *userServiceClient) BatchGetUsers(ctx context.Context, in *BatchGetUsersRequest, opts ...grpc.CallOption) (*BatchGetUsersResponse, error) {
+	out := new(BatchGetUsersResponse)
+	err := c.cc.Invoke(ctx, UserService_BatchGetUsers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *userServiceClient) UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UpdateUserResponse, error) {
 	out := new(UpdateUserResponse)
 	err := c.cc.Invoke(ctx, UserService_UpdateUser_FullMethodName, in, out, opts...)
@@ -82,7 +113,16 @@ func (c *userServiceClient) DeleteUser(ctx context.Context, in *DeleteUserReques
 	return out, nil
 }
 
-func (c *userServiceClient) ListUsers(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListUsersResponse, error) {
+func (c *userServiceClient) UndeleteUser(ctx context.Context, in *UndeleteUserRequest, opts ...grpc.CallOption) (*UndeleteUserResponse, error) {
+	out := new(UndeleteUserResponse)
+	err := c.cc.Invoke(ctx, UserService_UndeleteUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error) {
 	out := new(ListUsersResponse)
 	err := c.cc.Invoke(ctx, UserService_ListUsers_FullMethodName, in, out, opts...)
 	if err != nil {
@@ -91,15 +131,130 @@ func (c *userServiceClient) ListUsers(ctx context.Context, in *emptypb.Empty, op
 	return out, nil
 }
 
+func (c *userServiceClient) WatchUsers(ctx context.Context, in *WatchUsersRequest, opts ...grpc.CallOption) (UserService_WatchUsersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &UserService_ServiceDesc.Streams[0], UserService_WatchUsers_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userServiceWatchUsersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type UserService_WatchUsersClient interface {
+	Recv() (*UserEvent, error)
+	grpc.ClientStream
+}
+
+type userServiceWatchUsersClient struct {
+	grpc.ClientStream
+}
+
+func (x *userServiceWatchUsersClient) Recv() (*UserEvent, error) {
+	m := new(UserEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *userServiceClient) BulkCreateUsers(ctx context.Context, opts ...grpc.CallOption) (UserService_BulkCreateUsersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &UserService_ServiceDesc.Streams[1], UserService_BulkCreateUsers_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userServiceBulkCreateUsersClient{stream}
+	return x, nil
+}
+
+type UserService_BulkCreateUsersClient interface {
+	Send(*BulkCreateUsersRequest) error
+	CloseAndRecv() (*BulkCreateUsersResponse, error)
+	grpc.ClientStream
+}
+
+type userServiceBulkCreateUsersClient struct {
+	grpc.ClientStream
+}
+
+func (x *userServiceBulkCreateUsersClient) Send(m *BulkCreateUsersRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *userServiceBulkCreateUsersClient) CloseAndRecv() (*BulkCreateUsersResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BulkCreateUsersResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *userServiceClient) SyncUsers(ctx context.Context, opts ...grpc.CallOption) (UserService_SyncUsersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &UserService_ServiceDesc.Streams[2], UserService_SyncUsers_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userServiceSyncUsersClient{stream}
+	return x, nil
+}
+
+type UserService_SyncUsersClient interface {
+	Send(*SyncUsersRequest) error
+	Recv() (*SyncUsersResponse, error)
+	grpc.ClientStream
+}
+
+type userServiceSyncUsersClient struct {
+	grpc.ClientStream
+}
+
+func (x *userServiceSyncUsersClient) Send(m *SyncUsersRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *userServiceSyncUsersClient) Recv() (*SyncUsersResponse, error) {
+	m := new(SyncUsersResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // UserServiceServer is the server API for UserService service.
 // All implementations must embed UnimplementedUserServiceServer
 // for forward compatibility
 type UserServiceServer interface {
 	CreateUser(context.Context, *CreateUserRequest) (*CreateUserResponse, error)
 	GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error)
+	// BatchGetUsers gets several users in one call
+	BatchGetUsers(context.Context, *BatchGetUsersRequest) (*BatchGetUsersResponse, error)
 	UpdateUser(context.Context, *UpdateUserRequest) (*UpdateUserResponse, error)
+	// DeleteUser soft-deletes a user: it is hidden until undeleted, and purged
+	// after a retention period. Its username and email stay taken until then:
+	// reusing them fails with ALREADY_EXISTS whose ErrorInfo has deleted=true.
 	DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error)
-	ListUsers(context.Context, *emptypb.Empty) (*ListUsersResponse, error)
+	// UndeleteUser restores a soft-deleted user that wasn't purged yet.
+	UndeleteUser(context.Context, *UndeleteUserRequest) (*UndeleteUserResponse, error)
+	ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error)
+	// WatchUsers streams user changes. Slow consumers are disconnected with
+	// RESOURCE_EXHAUSTED and should resume from the last revision they got,
+	// OUT_OF_RANGE means that revision is no longer retained or comes from a
+	// previous run of the server.
+	WatchUsers(*WatchUsersRequest, UserService_WatchUsersServer) error
+	// BulkCreateUsers creates the users streamed by the client and reports the
+	// outcome of each one.
+	BulkCreateUsers(UserService_BulkCreateUsersServer) error
+	// SyncUsers reconciles users with the desired users streamed by the client,
+	// matched by username, and streams back the action taken for each one.
+	SyncUsers(UserService_SyncUsersServer) error
 	mustEmbedUnimplementedUserServiceServer()
 }
 
@@ -113,15 +268,30 @@ func (UnimplementedUserServiceServer) CreateUser(context.Context, *CreateUserReq
 func (UnimplementedUserServiceServer) GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
 }
+func (UnimplementedUserServiceServer) BatchGetUsers(context.Context, *BatchGetUsersRequest) (*BatchGetUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetUsers not implemented")
+}
 func (UnimplementedUserServiceServer) UpdateUser(context.Context, *UpdateUserRequest) (*UpdateUserResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
 }
 func (UnimplementedUserServiceServer) DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteUser not implemented")
 }
-func (UnimplementedUserServiceServer) ListUsers(context.Context, *emptypb.Empty) (*ListUsersResponse, error) {
+func (UnimplementedUserServiceServer) UndeleteUser(context.Context, *UndeleteUserRequest) (*UndeleteUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UndeleteUser not implemented")
+}
+func (UnimplementedUserServiceServer) ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
 }
+func (UnimplementedUserServiceServer) WatchUsers(*WatchUsersRequest, UserService_WatchUsersServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchUsers not implemented")
+}
+func (UnimplementedUserServiceServer) BulkCreateUsers(UserService_BulkCreateUsersServer) error {
+	return status.Errorf(codes.Unimplemented, "method BulkCreateUsers not implemented")
+}
+func (UnimplementedUserServiceServer) SyncUsers(UserService_SyncUsersServer) error {
+	return status.Errorf(codes.Unimplemented, "method SyncUsers not implemented")
+}
 func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
 
 // UnsafeUserServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -171,6 +341,24 @@ func _UserService_GetUser_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_BatchGetUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).BatchGetUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_BatchGetUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).BatchGetUsers(ctx, req.(*BatchGetUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _UserService_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateUserRequest)
 	if err := dec(in); err != nil {
@@ -207,8 +395,26 @@ func _UserService_DeleteUser_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_UndeleteUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndeleteUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).UndeleteUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_UndeleteUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).UndeleteUser(ctx, req.(*UndeleteUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _UserService_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+	in := new(ListUsersRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -220,11 +426,84 @@ func _UserService_ListUsers_Handler(srv interface{}, ctx context.Context, dec fu
 		FullMethod: UserService_ListUsers_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UserServiceServer).ListUsers(ctx, req.(*emptypb.Empty))
+		return srv.(UserServiceServer).ListUsers(ctx, req.(*ListUsersRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_WatchUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchUsersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UserServiceServer).WatchUsers(m, &userServiceWatchUsersServer{stream})
+}
+
+type UserService_WatchUsersServer interface {
+	Send(*UserEvent) error
+	grpc.ServerStream
+}
+
+type userServiceWatchUsersServer struct {
+	grpc.ServerStream
+}
+
+func (x *userServiceWatchUsersServer) Send(m *UserEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _UserService_BulkCreateUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UserServiceServer).BulkCreateUsers(&userServiceBulkCreateUsersServer{stream})
+}
+
+type UserService_BulkCreateUsersServer interface {
+	SendAndClose(*BulkCreateUsersResponse) error
+	Recv() (*BulkCreateUsersRequest, error)
+	grpc.ServerStream
+}
+
+type userServiceBulkCreateUsersServer struct {
+	grpc.ServerStream
+}
+
+func (x *userServiceBulkCreateUsersServer) SendAndClose(m *BulkCreateUsersResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *userServiceBulkCreateUsersServer) Recv() (*BulkCreateUsersRequest, error) {
+	m := new(BulkCreateUsersRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _UserService_SyncUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UserServiceServer).SyncUsers(&userServiceSyncUsersServer{stream})
+}
+
+type UserService_SyncUsersServer interface {
+	Send(*SyncUsersResponse) error
+	Recv() (*SyncUsersRequest, error)
+	grpc.ServerStream
+}
+
+type userServiceSyncUsersServer struct {
+	grpc.ServerStream
+}
+
+func (x *userServiceSyncUsersServer) Send(m *SyncUsersResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *userServiceSyncUsersServer) Recv() (*SyncUsersRequest, error) {
+	m := new(SyncUsersRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // UserService_ServiceDesc is the grpc.ServiceDesc for UserService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -240,6 +519,10 @@ var UserService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUser",
 			Handler:    _UserService_GetUser_Handler,
 		},
+		{
+			MethodName: "BatchGetUsers",
+			Handler:    _UserService_BatchGetUsers_Handler,
+		},
 		{
 			MethodName: "UpdateUser",
 			Handler:    _UserService_UpdateUser_Handler,
@@ -248,11 +531,32 @@ var UserService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteUser",
 			Handler:    _UserService_DeleteUser_Handler,
 		},
+		{
+			MethodName: "UndeleteUser",
+			Handler:    _UserService_UndeleteUser_Handler,
+		},
 		{
 			MethodName: "ListUsers",
 			Handler:    _UserService_ListUsers_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchUsers",
+			Handler:       _UserService_WatchUsers_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "BulkCreateUsers",
+			Handler:       _UserService_BulkCreateUsers_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "SyncUsers",
+			Handler:       _UserService_SyncUsers_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "api/proto/v1/user.proto",
 }
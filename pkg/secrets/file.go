@@ -0,0 +1,213 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// file is the content of a secrets file
+type file struct {
+	Keys []Key `json:"keys" yaml:"keys"`
+}
+
+// FileProvider provides the secrets listed in a JSON or YAML file, picked by
+// its extension, with the role and tenant of their key ID, and reloads them
+// when the file changes:
+//
+//	keys:
+//	  - id: key-a
+//	    secret: old-secret
+//	    role: editor
+//	    tenant: acme
+//	    expires_at: 2024-07-01T00:00:00Z
+//	  - id: key-a
+//	    secret: new-secret
+//	    role: editor
+//	    tenant: acme
+//	    not_before: 2024-06-01T00:00:00Z
+//
+// A key ID may be listed several times to rotate its secret, requests signed
+// with any of its valid secrets are accepted. Every entry must have a role
+// and a tenant, the same for all the entries of a key ID, so a key added to
+// the file never gets one by default.
+type FileProvider struct {
+	path string
+	now  func() time.Time
+	// checkRole fails for the role names that aren't valid
+	checkRole func(role string) error
+
+	mu   sync.RWMutex
+	keys map[string][]Key
+	// content is the loaded content of the file
+	content []byte
+}
+
+// FileOption configures a FileProvider
+type FileOption func(p *FileProvider)
+
+// WithRoleCheck makes loading a file fail when check fails for one of its roles
+func WithRoleCheck(check func(role string) error) FileOption {
+	return func(p *FileProvider) {
+		p.checkRole = check
+	}
+}
+
+// NewFileProvider loads the secrets of the file at path
+func NewFileProvider(path string, opts ...FileOption) (*FileProvider, error) {
+	p := &FileProvider{
+		path:      path,
+		now:       time.Now,
+		checkRole: func(string) error { return nil },
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if _, err := p.reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Secrets returns the secrets of keyID valid now
+func (p *FileProvider) Secrets(keyID string) ([]string, error) {
+	p.mu.RLock()
+	keys := p.keys[keyID]
+	p.mu.RUnlock()
+	return activeSecrets(keys, p.now())
+}
+
+// Role returns the role of keyID
+func (p *FileProvider) Role(keyID string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	keys := p.keys[keyID]
+	if len(keys) == 0 {
+		return "", false
+	}
+	return keys[0].Role, true
+}
+
+// Tenant returns the tenant keyID acts in
+func (p *FileProvider) Tenant(keyID string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	keys := p.keys[keyID]
+	if len(keys) == 0 {
+		return "", false
+	}
+	return keys[0].Tenant, true
+}
+
+// Watch reloads the file every interval when it changed, until ctx is done.
+// The secrets are kept as they are when the file can't be loaded.
+func (p *FileProvider) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := p.reload()
+			if err != nil {
+				log.Error().Err(err).Str("path", p.path).Msg("failed to reload HMAC secrets, keeping the current ones")
+				continue
+			}
+			if changed {
+				log.Info().Str("path", p.path).Strs("keys", p.keyIDs()).Msg("reloaded HMAC secrets")
+			}
+		}
+	}
+}
+
+// reload loads the file if it changed since it was last loaded
+func (p *FileProvider) reload() (bool, error) {
+	content, err := os.ReadFile(p.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+	p.mu.RLock()
+	unchanged := p.keys != nil && bytes.Equal(content, p.content)
+	p.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	keys, err := parseKeys(p.path, content, p.checkRole)
+	if err != nil {
+		return false, err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.content = content
+	p.mu.Unlock()
+	return true, nil
+}
+
+// keyIDs returns the loaded key IDs
+func (p *FileProvider) keyIDs() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	ids := make([]string, 0, len(p.keys))
+	for id := range p.keys {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// parseKeys decodes the content of the secrets file at path by key ID,
+// failing for the roles checkRole fails for
+func parseKeys(path string, content []byte, checkRole func(string) error) (map[string][]Key, error) {
+	var f file
+	switch ext := filepath.Ext(path); ext {
+	case ".json":
+		if err := json.Unmarshal(content, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(content, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported secrets file extension: %q", ext)
+	}
+
+	keys := make(map[string][]Key, len(f.Keys))
+	for i, key := range f.Keys {
+		// Errors name the key by ID or position, never by secret
+		switch {
+		case key.ID == "":
+			return nil, fmt.Errorf("key %d has no id", i)
+		case key.Secret == "":
+			return nil, fmt.Errorf("key %s has no secret", key.ID)
+		case key.Role == "":
+			return nil, fmt.Errorf("key %s has no role", key.ID)
+		case key.Tenant == "":
+			return nil, fmt.Errorf("key %s has no tenant", key.ID)
+		case len(keys[key.ID]) > 0 && keys[key.ID][0].Role != key.Role:
+			return nil, fmt.Errorf("key %s has several roles", key.ID)
+		case len(keys[key.ID]) > 0 && keys[key.ID][0].Tenant != key.Tenant:
+			return nil, fmt.Errorf("key %s has several tenants", key.ID)
+		case !key.NotBefore.IsZero() && !key.ExpiresAt.IsZero() && !key.NotBefore.Before(key.ExpiresAt):
+			return nil, fmt.Errorf("key %s expires before it is valid", key.ID)
+		}
+		if err := checkRole(key.Role); err != nil {
+			return nil, fmt.Errorf("key %s: %w", key.ID, err)
+		}
+		keys[key.ID] = append(keys[key.ID], key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("secrets file has no keys")
+	}
+	return keys, nil
+}
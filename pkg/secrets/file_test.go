@@ -0,0 +1,242 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeFile writes content to the file named name in dir
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newTestProvider loads path, at the time returned by now
+func newTestProvider(t *testing.T, path string, now *time.Time) *FileProvider {
+	t.Helper()
+	p, err := NewFileProvider(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.now = func() time.Time { return *now }
+	return p
+}
+
+func assertSecrets(t *testing.T, p *FileProvider, keyID string, want []string, wantErr error) {
+	t.Helper()
+	got, err := p.Secrets(keyID)
+	if !errors.Is(err, wantErr) {
+		t.Errorf("got error %v for %s, want %v", err, keyID, wantErr)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got secrets %v for %s, want %v", got, keyID, want)
+	}
+}
+
+func TestFileProviderValidity(t *testing.T) {
+	const yamlKeys = `
+keys:
+  - id: key-a
+    secret: old-secret
+    role: viewer
+    tenant: acme
+    expires_at: 2024-07-01T00:00:00Z
+  - id: key-a
+    secret: new-secret
+    role: viewer
+    tenant: acme
+    not_before: 2024-06-01T00:00:00Z
+  - id: key-b
+    secret: b-secret
+    role: viewer
+    tenant: acme
+    not_before: 2024-06-15T00:00:00Z
+    expires_at: 2024-08-01T00:00:00Z
+`
+	const jsonKeys = `{"keys": [
+  {"id": "key-a", "secret": "old-secret", "role": "viewer", "tenant": "acme", "expires_at": "2024-07-01T00:00:00Z"},
+  {"id": "key-a", "secret": "new-secret", "role": "viewer", "tenant": "acme", "not_before": "2024-06-01T00:00:00Z"},
+  {"id": "key-b", "secret": "b-secret", "role": "viewer", "tenant": "acme", "not_before": "2024-06-15T00:00:00Z", "expires_at": "2024-08-01T00:00:00Z"}
+]}`
+
+	for name, content := range map[string]string{"keys.yaml": yamlKeys, "keys.json": jsonKeys} {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+			p := newTestProvider(t, writeFile(t, t.TempDir(), name, content), &now)
+
+			for _, tc := range []struct {
+				at      time.Time
+				keyID   string
+				want    []string
+				wantErr error
+			}{
+				{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), "key-a", []string{"old-secret"}, nil},
+				{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), "key-b", nil, ErrKeyNotYetValid},
+				// Both secrets are accepted during the rotation
+				{time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), "key-a", []string{"old-secret", "new-secret"}, nil},
+				{time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC), "key-b", []string{"b-secret"}, nil},
+				{time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), "key-a", []string{"new-secret"}, nil},
+				{time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), "key-b", nil, ErrExpiredKey},
+				{time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), "key-c", nil, ErrUnknownKey},
+			} {
+				now = tc.at
+				assertSecrets(t, p, tc.keyID, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileProviderReload(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	path := writeFile(t, dir, "keys.yaml", `
+keys:
+  - id: key-a
+    secret: old-secret
+    role: viewer
+    tenant: acme
+`)
+	p := newTestProvider(t, path, &now)
+
+	if changed, err := p.reload(); err != nil || changed {
+		t.Errorf("got %v, %v reloading an unchanged file, want no change", changed, err)
+	}
+
+	// Rotate key-a and add key-b
+	writeFile(t, dir, "keys.yaml", `
+keys:
+  - id: key-a
+    secret: old-secret
+    role: viewer
+    tenant: acme
+    expires_at: 2024-06-02T00:00:00Z
+  - id: key-a
+    secret: new-secret
+    role: viewer
+    tenant: acme
+  - id: key-b
+    secret: b-secret
+    role: viewer
+    tenant: acme
+`)
+	if changed, err := p.reload(); err != nil || !changed {
+		t.Fatalf("got %v, %v reloading a changed file, want a change", changed, err)
+	}
+	assertSecrets(t, p, "key-a", []string{"old-secret", "new-secret"}, nil)
+	assertSecrets(t, p, "key-b", []string{"b-secret"}, nil)
+	now = now.Add(24 * time.Hour)
+	assertSecrets(t, p, "key-a", []string{"new-secret"}, nil)
+
+	// The loaded keys are kept when the file becomes invalid
+	for _, content := range []string{
+		"keys: [",
+		"keys: []",
+		"keys:\n  - id: key-a\n",
+		"keys:\n  - secret: a-secret\n",
+		"keys:\n  - id: key-a\n    secret: a-secret\n    role: viewer\n    tenant: acme\n    not_before: 2024-07-01T00:00:00Z\n    expires_at: 2024-06-01T00:00:00Z\n",
+	} {
+		writeFile(t, dir, "keys.yaml", content)
+		if _, err := p.reload(); err == nil {
+			t.Errorf("got no error reloading %q", content)
+		}
+		assertSecrets(t, p, "key-b", []string{"b-secret"}, nil)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.reload(); err == nil {
+		t.Error("got no error reloading a removed file")
+	}
+	assertSecrets(t, p, "key-b", []string{"b-secret"}, nil)
+}
+
+func TestFileProviderRolesAndTenants(t *testing.T) {
+	checkRole := func(role string) error {
+		if role != "viewer" && role != "editor" {
+			return fmt.Errorf("unknown role: %s", role)
+		}
+		return nil
+	}
+	dir := t.TempDir()
+	path := writeFile(t, dir, "keys.yaml", `
+keys:
+  - id: key-a
+    secret: a-secret
+    role: editor
+    tenant: acme
+`)
+	p, err := NewFileProvider(path, WithRoleCheck(checkRole))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A key added to the file comes with its own role and tenant
+	writeFile(t, dir, "keys.yaml", `
+keys:
+  - id: key-a
+    secret: a-secret
+    role: editor
+    tenant: acme
+  - id: key-b
+    secret: b-secret
+    role: viewer
+    tenant: globex
+`)
+	if _, err := p.reload(); err != nil {
+		t.Fatal(err)
+	}
+	for keyID, want := range map[string][2]string{"key-a": {"editor", "acme"}, "key-b": {"viewer", "globex"}} {
+		role, ok := p.Role(keyID)
+		if !ok || role != want[0] {
+			t.Errorf("got role %q, %v for %s, want %s", role, ok, keyID, want[0])
+		}
+		tenant, ok := p.Tenant(keyID)
+		if !ok || tenant != want[1] {
+			t.Errorf("got tenant %q, %v for %s, want %s", tenant, ok, keyID, want[1])
+		}
+	}
+	if _, ok := p.Role("key-c"); ok {
+		t.Error("got a role for an unknown key")
+	}
+	if _, ok := p.Tenant("key-c"); ok {
+		t.Error("got a tenant for an unknown key")
+	}
+
+	for name, content := range map[string]string{
+		"no role":         "keys:\n  - id: key-c\n    secret: c-secret\n    tenant: acme\n",
+		"no tenant":       "keys:\n  - id: key-c\n    secret: c-secret\n    role: viewer\n",
+		"unknown role":    "keys:\n  - id: key-c\n    secret: c-secret\n    role: owner\n    tenant: acme\n",
+		"several roles":   "keys:\n  - id: key-c\n    secret: c-secret\n    role: viewer\n    tenant: acme\n  - id: key-c\n    secret: c-new\n    role: editor\n    tenant: acme\n",
+		"several tenants": "keys:\n  - id: key-c\n    secret: c-secret\n    role: viewer\n    tenant: acme\n  - id: key-c\n    secret: c-new\n    role: viewer\n    tenant: globex\n",
+	} {
+		writeFile(t, dir, "keys.yaml", content)
+		if _, err := p.reload(); err == nil {
+			t.Errorf("got no error reloading a file with a key with %s", name)
+		}
+		if _, ok := p.Role("key-c"); ok {
+			t.Errorf("got key-c loaded from a file with a key with %s", name)
+		}
+	}
+}
+
+func TestNewFileProviderErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{
+		filepath.Join(dir, "missing.yaml"),
+		writeFile(t, dir, "keys.toml", `keys = []`),
+		writeFile(t, dir, "keys.json", `{"keys": [{"id": "key-a"}]}`),
+	} {
+		if _, err := NewFileProvider(path); err == nil {
+			t.Errorf("got no error loading %s", filepath.Base(path))
+		}
+	}
+}
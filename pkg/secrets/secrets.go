@@ -0,0 +1,76 @@
+// Package secrets provides the secrets HMAC keys sign requests with.
+package secrets
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnknownKey     = errors.New("unknown key")
+	ErrExpiredKey     = errors.New("key has expired")
+	ErrKeyNotYetValid = errors.New("key is not valid yet")
+)
+
+// Provider returns the secrets of HMAC key IDs
+type Provider interface {
+	// Secrets returns the secrets keyID can currently sign requests with,
+	// several while one is being rotated
+	Secrets(keyID string) ([]string, error)
+}
+
+// Static provides a secret that never expires to each key ID
+type Static map[string]string
+
+// Secrets returns the secret of keyID
+func (s Static) Secrets(keyID string) ([]string, error) {
+	secret, ok := s[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return []string{secret}, nil
+}
+
+// Key is a secret of a key ID with its validity period
+type Key struct {
+	ID     string `json:"id" yaml:"id"`
+	Secret string `json:"secret" yaml:"secret"`
+	// Role is the role of the key ID, e.g. "viewer", see middleware.Role
+	Role string `json:"role" yaml:"role"`
+	// Tenant is the tenant the key ID acts in
+	Tenant string `json:"tenant" yaml:"tenant"`
+	// NotBefore is when the secret becomes valid, it is valid right away when zero
+	NotBefore time.Time `json:"not_before" yaml:"not_before"`
+	// ExpiresAt is when the secret stops being valid, it never does when zero
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
+
+// activeSecrets returns the secrets of keys valid at now, or why there are none
+func activeSecrets(keys []Key, now time.Time) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, ErrUnknownKey
+	}
+
+	var (
+		active  []string
+		expired bool
+	)
+	for _, key := range keys {
+		switch {
+		case !key.ExpiresAt.IsZero() && !now.Before(key.ExpiresAt):
+			expired = true
+		case !key.NotBefore.IsZero() && now.Before(key.NotBefore):
+		default:
+			active = append(active, key.Secret)
+		}
+	}
+
+	switch {
+	case len(active) > 0:
+		return active, nil
+	case expired:
+		return nil, ErrExpiredKey
+	default:
+		return nil, ErrKeyNotYetValid
+	}
+}
@@ -0,0 +1,20 @@
+// Package tenant carries the tenant a request acts in through its context.
+package tenant
+
+import "context"
+
+// Default is the tenant of the users and keys that weren't assigned one
+const Default = "default"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx acting in tenant
+func NewContext(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, contextKey{}, tenant)
+}
+
+// FromContext returns the tenant ctx acts in
+func FromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(contextKey{}).(string)
+	return tenant, ok
+}
@@ -0,0 +1,142 @@
+// Package validate checks protobuf messages against the FieldRules declared
+// on their fields with the (api.proto.v1.validate) option.
+package validate
+
+import (
+	"fmt"
+	"github.com/msharbaji/grpc-go-example/pkg/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"net/mail"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// FieldViolation describes a field that breaks its rules
+type FieldViolation struct {
+	// Field is the path of the field, e.g. "username" or "user.email"
+	Field       string
+	Description string
+}
+
+// Error lists every rule broken by a message. It converts to a
+// codes.InvalidArgument status carrying a google.rpc.BadRequest.
+type Error struct {
+	Violations []FieldViolation
+}
+
+func (e *Error) Error() string {
+	descriptions := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		descriptions[i] = v.Field + ": " + v.Description
+	}
+	return "invalid request: " + strings.Join(descriptions, "; ")
+}
+
+// GRPCStatus returns the codes.InvalidArgument status of the error, see status.FromError
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(codes.InvalidArgument, e.Error())
+	badRequest := &errdetails.BadRequest{}
+	for _, v := range e.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	detailed, err := st.WithDetails(badRequest)
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+// patterns caches the compiled FieldRules patterns
+var patterns sync.Map
+
+// Validate checks msg and the messages it contains against their declared
+// rules. It returns nil or an *Error listing every violation.
+func Validate(msg proto.Message) error {
+	var violations []FieldViolation
+	validateMessage(msg.ProtoReflect(), "", &violations)
+	if len(violations) == 0 {
+		return nil
+	}
+	return &Error{Violations: violations}
+}
+
+func validateMessage(m protoreflect.Message, prefix string, violations *[]FieldViolation) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		path := prefix + string(fd.Name())
+
+		if fd.Kind() == protoreflect.MessageKind && !fd.IsList() && !fd.IsMap() {
+			if m.Has(fd) {
+				validateMessage(m.Get(fd).Message(), path+".", violations)
+			}
+			continue
+		}
+
+		rules, ok := proto.GetExtension(fd.Options(), pb.E_Validate).(*pb.FieldRules)
+		if !ok || rules == nil || fd.Kind() != protoreflect.StringKind || fd.IsList() || fd.IsMap() {
+			continue
+		}
+		if fd.HasPresence() && !m.Has(fd) {
+			// Optional fields are only checked when set
+			continue
+		}
+		if description := check(m.Get(fd).String(), rules); description != "" {
+			*violations = append(*violations, FieldViolation{Field: path, Description: description})
+		}
+	}
+}
+
+// check returns why value breaks rules, or an empty string
+func check(value string, rules *pb.FieldRules) string {
+	if value == "" {
+		if rules.GetRequired() {
+			return "must not be empty"
+		}
+		return ""
+	}
+
+	length := utf8.RuneCountInString(value)
+	if min := rules.GetMinLen(); min > 0 && length < int(min) {
+		return fmt.Sprintf("must be at least %d characters long", min)
+	}
+	if max := rules.GetMaxLen(); max > 0 && length > int(max) {
+		return fmt.Sprintf("must be at most %d characters long", max)
+	}
+	if rules.GetEmail() {
+		if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+			return "must be a valid email address"
+		}
+	}
+	if pattern := rules.GetPattern(); pattern != "" {
+		re, err := compile(pattern)
+		if err != nil {
+			return fmt.Sprintf("has an invalid pattern rule: %s", pattern)
+		}
+		if !re.MatchString(value) {
+			return fmt.Sprintf("must match %s", pattern)
+		}
+	}
+	return ""
+}
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil //nolint:forcetypeassert
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}